@@ -1,6 +1,7 @@
 package apply
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
 )
 
 var (
@@ -74,9 +76,17 @@ func New(config Config) (*Command, error) {
 	newCommand.mainCommand.Flags().StringVarP(&flags.sourceFile, "dump-file", "f", "", "Filename that contains the yaml-resources for migration")
 	newCommand.mainCommand.Flags().StringVarP(&flags.dstMC, "destination", "d", "", "Name of the destination MC")
 	newCommand.mainCommand.Flags().StringVarP(&flags.srcMC, "source", "s", "", "Name of the source MC")
-	newCommand.mainCommand.Flags().StringVarP(&flags.wcName, "wc-name", "n", "", "Name of the WC to migrate")
+	newCommand.mainCommand.Flags().StringVarP(&flags.wcName, "wc-name", "n", "", "Name of the source WC, the destination WC name is read from the dump file")
 	newCommand.mainCommand.Flags().StringVarP(&flags.orgNamespace, "org-namespace", "o", "", "Namespace of organization in capi, eg. org-foobar")
 	newCommand.mainCommand.Flags().BoolVarP(&flags.finalizer, "finalizer", "z", false, "Remove finalizers in the sourceMC. Setting this might result in leftover finalizers")
+	newCommand.mainCommand.Flags().StringVar(&flags.encryptionKey, "encryption-key", "", "age key file used to decrypt secrets in the dump file, required if prepare was run with --encryption-key")
+	newCommand.mainCommand.Flags().BoolVar(&flags.wait, "wait", false, "Wait for the applied apps to be deployed, see the verify command")
+	newCommand.mainCommand.Flags().DurationVar(&flags.verifyTimeout, "verify-timeout", 10*time.Minute, "Time to wait for all apps to be deployed when --wait is set")
+	newCommand.mainCommand.Flags().DurationVar(&flags.prerequisiteTimeout, "prerequisite-timeout", 30*time.Minute, "Time to wait for the prerequisites on the destination MC before applying, 0 waits forever; --wait is limited by --verify-timeout")
+	newCommand.mainCommand.Flags().StringSliceVar(&flags.prerequisites, "prerequisite", nil, "Object which must exist on the destination MC before the apps are applied, as kind/name or kind/namespace/name, {wc} is replaced with the WC name. Replaces the default cluster values and kubeconfig")
+
+	flags.connection.AddSourceFlags(newCommand.mainCommand.Flags())
+	flags.connection.AddDestinationFlags(newCommand.mainCommand.Flags())
 
 	return newCommand, nil
 }
@@ -86,15 +96,21 @@ func (c *Command) CobraCommand() *cobra.Command {
 }
 
 func (c *Command) Execute(cmd *cobra.Command, args []string) error {
+	result := output.NewResult(CommandUse)
 
 	err := flags.Validate()
 	if err != nil {
-		return microerror.Mask(err)
+		return microerror.Mask(output.Write(result, err))
 	}
 
 	//c.logger = debug.MustWrapDebugLogger(c.logger, "error")
 
-	err = c.execute()
+	err = c.execute(cmd.Context(), result)
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = output.Write(result, nil)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -102,24 +118,44 @@ func (c *Command) Execute(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func (c *Command) execute() error {
+func (c *Command) execute(ctx context.Context, result *output.Result) error {
+	var prerequisites []cluster.Prerequisite
+	for _, s := range flags.prerequisites {
+		prerequisite, err := cluster.ParsePrerequisite(s)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		prerequisites = append(prerequisites, prerequisite)
+	}
 
-	mcs, err := cluster.Login(flags.srcMC, flags.dstMC)
+	mcs, err := cluster.Login(flags.connection.Source(flags.srcMC), flags.connection.Destination(flags.dstMC))
 	if err != nil {
 		return microerror.Mask(err)
 	}
+	result.AddConnections(mcs)
 	mcs.WcName = flags.wcName
 	mcs.SrcMC.Namespace = flags.wcName
 	mcs.OrgNamespace = flags.orgNamespace
 	mcs.BackOff = backoff.NewMaxRetries(15, 3*time.Second)
+	mcs.Prerequisites = prerequisites
+	mcs.PrerequisiteTimeout = flags.prerequisiteTimeout
+
+	if flags.encryptionKey != "" {
+		mcs.EncryptionKey, err = cluster.LoadEncryptionKey(flags.encryptionKey)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
 
-	err = mcs.ApplyCAPIApps(flags.sourceFile)
+	applied, err := mcs.ApplyCAPIApps(ctx, flags.sourceFile)
+	result.Objects = applied
 	if err != nil {
 		if errors.Is(err, cluster.MigrationFileEmpty) {
 			color.Red("⚠  Warning")
 			color.Red("⚠  No apps targeted for migration")
 			color.Red("⚠  The given file was empty")
 			color.Red("⚠  Warning")
+			result.AddWarning("No apps targeted for migration, the given file was empty")
 
 			return nil
 		}
@@ -127,6 +163,18 @@ func (c *Command) execute() error {
 		return microerror.Mask(err)
 	}
 
+	if flags.wait {
+		mcs.BackOff = backoff.NewConstant(flags.verifyTimeout, 10*time.Second)
+
+		verifications, err := mcs.VerifyCAPIApps(ctx, flags.sourceFile)
+		mcs.PrintAppVerifications(verifications)
+		result.Verifications = verifications
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		color.Green("All migrated apps are deployed.")
+	}
+
 	if flags.finalizer {
 		err = mcs.SrcMC.RemoveFinalizerOnNamespace()
 		if err != nil {
@@ -1,17 +1,29 @@
 package apply
 
 import (
+	"time"
+
 	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/cmd/connection"
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
 )
 
 // Flags represents all the flags that can be set via the command line
 type Flags struct {
-	sourceFile   string
-	dstMC        string
-	srcMC        string
-	wcName       string
-	finalizer    bool
-	orgNamespace string
+	sourceFile          string
+	dstMC               string
+	srcMC               string
+	wcName              string
+	finalizer           bool
+	orgNamespace        string
+	encryptionKey       string
+	wait                bool
+	verifyTimeout       time.Duration
+	prerequisiteTimeout time.Duration
+	prerequisites       []string
+
+	connection connection.Flags
 }
 
 func (f *Flags) Validate() error {
@@ -31,5 +43,21 @@ func (f *Flags) Validate() error {
 		return microerror.Maskf(invalidFlagsError, "OrgNamespace must not be empty")
 	}
 
+	if f.prerequisiteTimeout < 0 {
+		return microerror.Maskf(invalidFlagsError, "PrerequisiteTimeout must not be negative")
+	}
+
+	for _, prerequisite := range f.prerequisites {
+		_, err := cluster.ParsePrerequisite(prerequisite)
+		if err != nil {
+			return microerror.Maskf(invalidFlagsError, "Prerequisite is invalid: %s", err)
+		}
+	}
+
+	err := f.connection.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
 	return nil
 }
@@ -0,0 +1,241 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
+	"github.com/giantswarm/app-migration-cli/pkg/batch"
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
+)
+
+var (
+	flags = &Flags{}
+)
+
+const (
+	// CommandUse indicates the general syntax of the command
+	CommandUse = "batch"
+
+	// CommandShort describes the command in a short list
+	CommandShort = "Run a stage of the app migration for many WCs from a plan file"
+
+	// CommandLong documents the command in full length
+	CommandLong = `Run preflight, prepare or apply for every WC listed in a plan file.
+  All WCs of a plan are migrated between the same pair of MCs.
+
+  sourceMC: gauss
+  destinationMC: golem
+  clusters:
+  - wcName: wc1
+    orgNamespace: org-foobar
+    outputFile: gauss-wc1-apps.yaml
+
+  Prepare all WCs of the plan, four at a time:
+
+  ./app-migration-cli batch -p plan.yaml --stage prepare --parallelism 4
+  `
+)
+
+// Config represents the configuration used to create a new command.
+type Config struct {
+	// Settings.
+	MainCommand *cobra.Command
+	Logger      micrologger.Logger
+}
+
+type Command struct {
+	// Dependencies.
+	logger micrologger.Logger
+
+	// Settings.
+	mainCommand *cobra.Command
+}
+
+// New creates a new configured command.
+func New(config Config) (*Command, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	newCommand := &Command{
+		// Dependencies.
+		logger: config.Logger,
+
+		// Internals.
+		mainCommand: nil,
+	}
+
+	newCommand.mainCommand = &cobra.Command{
+		Use:   CommandUse,
+		Short: CommandShort,
+		Long:  CommandLong,
+		RunE:  newCommand.Execute,
+	}
+
+	newCommand.mainCommand.Flags().StringVarP(&flags.planFile, "plan", "p", "", "Plan file listing the source/destination MC and the WCs to migrate")
+	newCommand.mainCommand.Flags().StringVar(&flags.stage, "stage", "", fmt.Sprintf("Stage to run for every WC, one of %v", batch.Stages))
+	newCommand.mainCommand.Flags().IntVar(&flags.parallelism, "parallelism", 4, "Maximum number of WCs processed at the same time")
+	newCommand.mainCommand.Flags().BoolVarP(&flags.finalizer, "finalizer", "z", false, "Apply finalizers to the source namespaces in prepare and remove them in apply")
+	newCommand.mainCommand.Flags().StringVar(&flags.encryptionKey, "encryption-key", "", "age key file used to encrypt secrets in prepare and decrypt them in apply")
+	newCommand.mainCommand.Flags().StringVar(&flags.filterRules, "filter-rules", "", "Rules file deciding which apps are migrated, the built-in rules are used if not set")
+	newCommand.mainCommand.Flags().StringVar(&flags.metadataRules, "metadata-rules", "", "Rules file rewriting the labels and annotations of the migrated objects, the built-in rules are used if not set")
+	newCommand.mainCommand.Flags().StringVar(&flags.upgradeRules, "upgrade-rules", "", "Rules file changing the version and catalog of apps which do not run on CAPI")
+	newCommand.mainCommand.Flags().StringVar(&flags.valuesRules, "values-rules", "", "Rules file transforming the values of the migrated config maps and secrets")
+	newCommand.mainCommand.Flags().DurationVar(&flags.prerequisiteTimeout, "prerequisite-timeout", 30*time.Minute, "Time to wait for the prerequisites of each WC in apply, 0 waits forever")
+	newCommand.mainCommand.Flags().StringSliceVar(&flags.prerequisites, "prerequisite", nil, "Object which must exist on the destination MC before the apps are applied, as kind/name or kind/namespace/name, {wc} is replaced with the WC name. Replaces the default cluster values and kubeconfig")
+
+	flags.connection.AddSourceFlags(newCommand.mainCommand.Flags())
+	flags.connection.AddDestinationFlags(newCommand.mainCommand.Flags())
+
+	return newCommand, nil
+}
+
+// clusterResult is the outcome for a single WC in the result document.
+type clusterResult struct {
+	WcName   string `json:"wcName"`
+	Stage    string `json:"stage"`
+	Result   string `json:"result"`
+	Duration string `json:"duration"`
+	Message  string `json:"message"`
+}
+
+func (c *Command) CobraCommand() *cobra.Command {
+	return c.mainCommand
+}
+
+func (c *Command) Execute(cmd *cobra.Command, args []string) error {
+	result := output.NewResult(CommandUse)
+
+	err := flags.Validate()
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = c.execute(cmd.Context(), result)
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = output.Write(result, nil)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *Command) execute(ctx context.Context, result *output.Result) error {
+	plan, err := batch.LoadPlan(flags.planFile)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	mcs, err := cluster.Login(flags.connection.Source(plan.SourceMC), flags.connection.Destination(plan.DestinationMC))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	result.AddConnections(mcs)
+
+	config := batch.Config{
+		Stage:       batch.Stage(flags.stage),
+		Parallelism: flags.parallelism,
+		Finalizer:   flags.finalizer,
+		Out:         output.HumanWriter(),
+	}
+
+	if flags.encryptionKey != "" {
+		config.EncryptionKey, err = cluster.LoadEncryptionKey(flags.encryptionKey)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	if flags.filterRules != "" {
+		config.Rules, err = apps.LoadRules(flags.filterRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	if flags.metadataRules != "" {
+		config.MetadataRules, err = cluster.LoadMetadataRules(flags.metadataRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	if flags.upgradeRules != "" {
+		config.UpgradeRules, err = cluster.LoadUpgradeRules(flags.upgradeRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	if flags.valuesRules != "" {
+		config.ValuesRules, err = cluster.LoadValuesRules(flags.valuesRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	color.Yellow("Running %s for %d WCs: %s -> %s", config.Stage, len(plan.Clusters), plan.SourceMC, plan.DestinationMC)
+
+	for _, s := range flags.prerequisites {
+		prerequisite, err := cluster.ParsePrerequisite(s)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		config.Prerequisites = append(config.Prerequisites, prerequisite)
+	}
+	config.PrerequisiteTimeout = flags.prerequisiteTimeout
+
+	results, err := batch.Run(ctx, mcs, plan, config)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var failed int
+	var clusterResults []clusterResult
+	w := tabwriter.NewWriter(output.HumanWriter(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "\nWC\tSTAGE\tRESULT\tDURATION\tMESSAGE")
+	for _, r := range results {
+		status := "ok"
+		message := r.Message
+		if r.Err != nil {
+			failed++
+			status = "failed"
+			message = r.Err.Error()
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.WcName, r.Stage, status, r.Duration.Round(time.Millisecond), message)
+
+		clusterResults = append(clusterResults, clusterResult{
+			WcName:   r.WcName,
+			Stage:    string(r.Stage),
+			Result:   status,
+			Duration: r.Duration.Round(time.Millisecond).String(),
+			Message:  message,
+		})
+	}
+	_ = w.Flush()
+
+	result.Objects = clusterResults
+
+	if failed > 0 {
+		return microerror.Maskf(batchFailedError, "%s failed for %d of %d WCs", config.Stage, failed, len(results))
+	}
+
+	color.Green("%s succeeded for all %d WCs", config.Stage, len(results))
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package batch
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidFlagsError = &microerror.Error{
+	Kind: "invalidFlagsError",
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+var batchFailedError = &microerror.Error{
+	Kind: "batchFailedError",
+}
@@ -0,0 +1,61 @@
+package batch
+
+import (
+	"slices"
+	"time"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/cmd/connection"
+	"github.com/giantswarm/app-migration-cli/pkg/batch"
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+)
+
+// Flags represents all the flags that can be set via the command line
+type Flags struct {
+	planFile            string
+	stage               string
+	parallelism         int
+	finalizer           bool
+	encryptionKey       string
+	filterRules         string
+	metadataRules       string
+	upgradeRules        string
+	valuesRules         string
+	prerequisiteTimeout time.Duration
+	prerequisites       []string
+
+	connection connection.Flags
+}
+
+func (f *Flags) Validate() error {
+	if f.planFile == "" {
+		return microerror.Maskf(invalidFlagsError, "Plan must not be empty")
+	}
+
+	if !slices.Contains(batch.Stages, batch.Stage(f.stage)) {
+		return microerror.Maskf(invalidFlagsError, "Stage must be one of %v", batch.Stages)
+	}
+
+	if f.parallelism < 1 {
+		return microerror.Maskf(invalidFlagsError, "Parallelism must be at least 1")
+	}
+
+	if f.prerequisiteTimeout < 0 {
+		return microerror.Maskf(invalidFlagsError, "PrerequisiteTimeout must not be negative")
+	}
+
+	for _, prerequisite := range f.prerequisites {
+		_, err := cluster.ParsePrerequisite(prerequisite)
+		if err != nil {
+			return microerror.Maskf(invalidFlagsError, "Prerequisite is invalid: %s", err)
+		}
+	}
+
+	err := f.connection.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
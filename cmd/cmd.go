@@ -2,14 +2,25 @@ package cmd
 
 import (
 	"github.com/giantswarm/app-migration-cli/cmd/apply"
+	"github.com/giantswarm/app-migration-cli/cmd/batch"
+	"github.com/giantswarm/app-migration-cli/cmd/diff"
 	"github.com/giantswarm/app-migration-cli/cmd/preflight"
 	"github.com/giantswarm/app-migration-cli/cmd/prepare"
+	"github.com/giantswarm/app-migration-cli/cmd/restore"
+	"github.com/giantswarm/app-migration-cli/cmd/rollback"
+	"github.com/giantswarm/app-migration-cli/cmd/snapshot"
+	"github.com/giantswarm/app-migration-cli/cmd/verify"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/spf13/cobra"
 )
 
+var (
+	flags = &Flags{}
+)
+
 const (
 	// CommandUse indicates the general syntax of the command
 	CommandUse = "app-migration-cli"
@@ -50,8 +61,12 @@ func New(config Config) (*Command, error) {
 		Short: CommandShort,
 		Long:  CommandLong,
 		RunE:  newCommand.Execute,
+
+		PersistentPreRunE: newCommand.PersistentPreRunE,
 	}
 
+	newCommand.cobraCommand.PersistentFlags().StringVar(&flags.output, "output", string(output.FormatText), "Output format, one of text or json. With json a single result document is printed to stdout and all other output goes to stderr")
+
 	var preflightCommand *preflight.Command
 	{
 		c := preflight.Config{
@@ -91,9 +106,93 @@ func New(config Config) (*Command, error) {
 		}
 	}
 
+	var diffCommand *diff.Command
+	{
+		c := diff.Config{
+			MainCommand: newCommand.cobraCommand,
+			Logger:      config.Logger,
+		}
+
+		diffCommand, err = diff.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var verifyCommand *verify.Command
+	{
+		c := verify.Config{
+			MainCommand: newCommand.cobraCommand,
+			Logger:      config.Logger,
+		}
+
+		verifyCommand, err = verify.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var rollbackCommand *rollback.Command
+	{
+		c := rollback.Config{
+			MainCommand: newCommand.cobraCommand,
+			Logger:      config.Logger,
+		}
+
+		rollbackCommand, err = rollback.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var batchCommand *batch.Command
+	{
+		c := batch.Config{
+			MainCommand: newCommand.cobraCommand,
+			Logger:      config.Logger,
+		}
+
+		batchCommand, err = batch.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var snapshotCommand *snapshot.Command
+	{
+		c := snapshot.Config{
+			MainCommand: newCommand.cobraCommand,
+			Logger:      config.Logger,
+		}
+
+		snapshotCommand, err = snapshot.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var restoreCommand *restore.Command
+	{
+		c := restore.Config{
+			MainCommand: newCommand.cobraCommand,
+			Logger:      config.Logger,
+		}
+
+		restoreCommand, err = restore.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	newCommand.cobraCommand.AddCommand(preflightCommand.CobraCommand())
 	newCommand.cobraCommand.AddCommand(prepareCommand.CobraCommand())
+	newCommand.cobraCommand.AddCommand(diffCommand.CobraCommand())
 	newCommand.cobraCommand.AddCommand(applyCommand.CobraCommand())
+	newCommand.cobraCommand.AddCommand(verifyCommand.CobraCommand())
+	newCommand.cobraCommand.AddCommand(rollbackCommand.CobraCommand())
+	newCommand.cobraCommand.AddCommand(batchCommand.CobraCommand())
+	newCommand.cobraCommand.AddCommand(snapshotCommand.CobraCommand())
+	newCommand.cobraCommand.AddCommand(restoreCommand.CobraCommand())
 
 	return newCommand, nil
 }
@@ -103,6 +202,21 @@ func (c *Command) CobraCommand() *cobra.Command {
 	return c.cobraCommand
 }
 
+// PersistentPreRunE selects the output format for all subcommands.
+func (c *Command) PersistentPreRunE(cmd *cobra.Command, args []string) error {
+	err := flags.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = output.SetFormat(output.Format(flags.output))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
 // Execute is called to actuall run the main command
 func (c *Command) Execute(cmd *cobra.Command, args []string) error {
 	cmd.HelpFunc()(cmd, nil)
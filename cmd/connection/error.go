@@ -0,0 +1,9 @@
+package connection
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidFlagsError = &microerror.Error{
+	Kind: "invalidFlagsError",
+}
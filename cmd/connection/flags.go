@@ -0,0 +1,100 @@
+// Package connection holds the flags which select how commands connect to the
+// source and destination MC.
+package connection
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/pflag"
+
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
+)
+
+// Flags represents the connection flags of a command
+type Flags struct {
+	srcKubeconfig string
+	srcContext    string
+	srcInCluster  bool
+	dstKubeconfig string
+	dstContext    string
+	dstInCluster  bool
+	loginProvider string
+	loginCommand  string
+
+	// set by Validate
+	provider cluster.LoginProvider
+}
+
+// AddSourceFlags registers the flags for the source MC.
+func (f *Flags) AddSourceFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.srcKubeconfig, "source-kubeconfig", "", "Kubeconfig file of the source MC, defaults to $KUBECONFIG or ~/.kube/config")
+	fs.StringVar(&f.srcContext, "source-context", "", "Kubeconfig context of the source MC, defaults to gs-<source>")
+	fs.BoolVar(&f.srcInCluster, "source-in-cluster", false, "Connect to the source MC with the service account of the pod the tool runs in")
+	f.addLoginFlags(fs)
+}
+
+// AddDestinationFlags registers the flags for the destination MC.
+func (f *Flags) AddDestinationFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.dstKubeconfig, "destination-kubeconfig", "", "Kubeconfig file of the destination MC, defaults to $KUBECONFIG or ~/.kube/config")
+	fs.StringVar(&f.dstContext, "destination-context", "", "Kubeconfig context of the destination MC, defaults to gs-<destination>")
+	fs.BoolVar(&f.dstInCluster, "destination-in-cluster", false, "Connect to the destination MC with the service account of the pod the tool runs in")
+	f.addLoginFlags(fs)
+}
+
+func (f *Flags) addLoginFlags(fs *pflag.FlagSet) {
+	if fs.Lookup("login-provider") != nil {
+		return
+	}
+
+	fs.StringVar(&f.loginProvider, "login-provider", cluster.LoginProviderOpsctl, fmt.Sprintf("Login used if a context does not exist, one of %s", strings.Join(cluster.LoginProviders, ", ")))
+	fs.StringVar(&f.loginCommand, "login-command", "", "Command run by the exec login provider, {mc}, {context} and {kubeconfig} are replaced")
+}
+
+func (f *Flags) Validate() error {
+	if f.srcInCluster && (f.srcKubeconfig != "" || f.srcContext != "") {
+		return microerror.Maskf(invalidFlagsError, "SourceInCluster can not be combined with SourceKubeconfig or SourceContext")
+	}
+
+	if f.dstInCluster && (f.dstKubeconfig != "" || f.dstContext != "") {
+		return microerror.Maskf(invalidFlagsError, "DestinationInCluster can not be combined with DestinationKubeconfig or DestinationContext")
+	}
+
+	if f.srcInCluster && f.dstInCluster {
+		return microerror.Maskf(invalidFlagsError, "Only one of SourceInCluster and DestinationInCluster can be set")
+	}
+
+	var err error
+	f.provider, err = cluster.NewLoginProvider(f.loginProvider, f.loginCommand)
+	if err != nil {
+		return microerror.Maskf(invalidFlagsError, "LoginProvider is invalid: %s", err)
+	}
+
+	return nil
+}
+
+// Source returns the connection config of the source MC.
+func (f *Flags) Source(name string) cluster.ConnectionConfig {
+	return cluster.ConnectionConfig{
+		Name:          name,
+		Kubeconfig:    f.srcKubeconfig,
+		Context:       f.srcContext,
+		InCluster:     f.srcInCluster,
+		LoginProvider: f.provider,
+		Out:           output.HumanWriter(),
+	}
+}
+
+// Destination returns the connection config of the destination MC.
+func (f *Flags) Destination(name string) cluster.ConnectionConfig {
+	return cluster.ConnectionConfig{
+		Name:          name,
+		Kubeconfig:    f.dstKubeconfig,
+		Context:       f.dstContext,
+		InCluster:     f.dstInCluster,
+		LoginProvider: f.provider,
+		Out:           output.HumanWriter(),
+	}
+}
@@ -0,0 +1,187 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
+)
+
+var (
+	flags = &Flags{}
+)
+
+const (
+	// CommandUse indicates the general syntax of the command
+	CommandUse = "diff"
+
+	// CommandShort describes the command in a short list
+	CommandShort = "Show what the apply stage would change on the destination MC"
+
+	// CommandLong documents the command in full length
+	CommandLong = `Compare the apps and additional config from disk with the
+  live objects on the destination MC. Secret values are redacted. It operates read-only
+  and only connects to the destination MC.
+
+  Show the changes of a migration to golem:
+
+  ./app-migration-cli diff -f test25-apps.yaml -d golem -n wc1 -o org-foobar
+  `
+)
+
+// Config represents the configuration used to create a new command.
+type Config struct {
+	// Settings.
+	MainCommand *cobra.Command
+	Logger      micrologger.Logger
+}
+
+type Command struct {
+	// Dependencies.
+	logger micrologger.Logger
+
+	// Settings.
+	mainCommand *cobra.Command
+}
+
+// New creates a new configured command.
+func New(config Config) (*Command, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	newCommand := &Command{
+		// Dependencies.
+		logger: config.Logger,
+
+		// Internals.
+		mainCommand: nil,
+	}
+
+	newCommand.mainCommand = &cobra.Command{
+		Use:   CommandUse,
+		Short: CommandShort,
+		Long:  CommandLong,
+		RunE:  newCommand.Execute,
+	}
+
+	newCommand.mainCommand.Flags().StringVarP(&flags.sourceFile, "dump-file", "f", "", "Filename that contains the yaml-resources for migration, required")
+	newCommand.mainCommand.Flags().StringVarP(&flags.dstMC, "destination", "d", "", "Name of the destination MC")
+	newCommand.mainCommand.Flags().StringVarP(&flags.srcMC, "source", "s", "", "Name of the source MC, not used")
+	newCommand.mainCommand.Flags().StringVarP(&flags.wcName, "wc-name", "n", "", "Name of the source WC, the destination WC name is read from the dump file")
+	newCommand.mainCommand.Flags().StringVarP(&flags.orgNamespace, "org-namespace", "o", "", "Namespace of organization in capi, eg. org-foobar")
+	newCommand.mainCommand.Flags().StringVar(&flags.encryptionKey, "encryption-key", "", "age key file used to decrypt secrets in the dump file, required if prepare was run with --encryption-key")
+
+	flags.connection.AddDestinationFlags(newCommand.mainCommand.Flags())
+
+	// diff only reads the destination MC, -s is accepted for existing scripts
+	_ = newCommand.mainCommand.Flags().MarkDeprecated("source", "diff only connects to the destination MC")
+
+	return newCommand, nil
+}
+
+func (c *Command) CobraCommand() *cobra.Command {
+	return c.mainCommand
+}
+
+func (c *Command) Execute(cmd *cobra.Command, args []string) error {
+	result := output.NewResult(CommandUse)
+
+	err := flags.Validate()
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = c.execute(cmd.Context(), result)
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = output.Write(result, nil)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *Command) execute(ctx context.Context, result *output.Result) error {
+	dstMC, err := cluster.LoginMC(flags.connection.Destination(flags.dstMC))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	mcs := &cluster.Cluster{
+		DstMC:        dstMC,
+		WcName:       flags.wcName,
+		OrgNamespace: flags.orgNamespace,
+		Out:          output.HumanWriter(),
+	}
+	result.AddConnections(mcs)
+
+	if flags.encryptionKey != "" {
+		mcs.EncryptionKey, err = cluster.LoadEncryptionKey(flags.encryptionKey)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	diffs, err := mcs.DiffCAPIApps(ctx, flags.sourceFile)
+	if err != nil {
+		if errors.Is(err, cluster.MigrationFileEmpty) {
+			color.Red("⚠  Warning")
+			color.Red("⚠  No apps targeted for migration")
+			color.Red("⚠  The given file was empty")
+			color.Red("⚠  Warning")
+			result.AddWarning("No apps targeted for migration, the given file was empty")
+
+			return nil
+		}
+
+		return microerror.Mask(err)
+	}
+
+	result.Objects = diffs
+
+	summary := map[cluster.DiffResult]int{}
+	for _, diff := range diffs {
+		summary[diff.Result]++
+
+		if diff.Result == cluster.DiffResultIdentical {
+			continue
+		}
+
+		printDiff(diff.Diff)
+	}
+
+	_, _ = fmt.Fprintln(output.HumanWriter())
+	color.Yellow("%d new, %d changed, %d identical objects", summary[cluster.DiffResultNew], summary[cluster.DiffResultChanged], summary[cluster.DiffResultIdentical])
+
+	return nil
+}
+
+func printDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			_, _ = fmt.Fprintln(output.HumanWriter(), line)
+		case strings.HasPrefix(line, "+"):
+			color.Green(line)
+		case strings.HasPrefix(line, "-"):
+			color.Red(line)
+		case strings.HasPrefix(line, "@@"):
+			color.Cyan(line)
+		default:
+			_, _ = fmt.Fprintln(output.HumanWriter(), line)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package diff
+
+import (
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/cmd/connection"
+)
+
+// Flags represents all the flags that can be set via the command line
+type Flags struct {
+	sourceFile    string
+	dstMC         string
+	srcMC         string
+	wcName        string
+	orgNamespace  string
+	encryptionKey string
+
+	connection connection.Flags
+}
+
+func (f *Flags) Validate() error {
+	if f.sourceFile == "" {
+		return microerror.Maskf(invalidFlagsError, "DumpFile must not be empty")
+	}
+
+	if f.dstMC == "" {
+		return microerror.Maskf(invalidFlagsError, "DestinationMC must not be empty")
+	}
+
+	if f.wcName == "" {
+		return microerror.Maskf(invalidFlagsError, "WorkloadClusterName must not be empty")
+	}
+
+	if f.orgNamespace == "" {
+		return microerror.Maskf(invalidFlagsError, "OrgNamespace must not be empty")
+	}
+
+	err := f.connection.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
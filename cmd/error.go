@@ -0,0 +1,9 @@
+package cmd
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidFlagsError = &microerror.Error{
+	Kind: "invalidFlagsError",
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"slices"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/pkg/output"
+)
+
+// Flags represents all the flags that can be set via the command line
+type Flags struct {
+	output string
+}
+
+func (f *Flags) Validate() error {
+	if !slices.Contains(output.Formats, output.Format(f.output)) {
+		return microerror.Maskf(invalidFlagsError, "Output must be one of %v", output.Formats)
+	}
+
+	return nil
+}
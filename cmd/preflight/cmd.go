@@ -1,6 +1,9 @@
 package preflight
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
@@ -9,6 +12,7 @@ import (
 
 	"github.com/giantswarm/app-migration-cli/pkg/apps"
 	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
 )
 
 var (
@@ -24,11 +28,13 @@ const (
 
 	// CommandLong documents the command in full length
 	CommandLong = `Check if the source MC has apps which can be migrated. Checks
-  if the destination MC has the neccessary resources ready to allow running the apps. It operates read-only.
+  if the destination MC has the neccessary resources ready to allow running the apps:
+  the org namespace, the Organization CR and the catalogs of the apps must exist, the
+  migrated apps and their config should not exist yet. It operates read-only.
 
   Check a migration from gauss to golem:
 
-  ./app-migration-cli preflight -s gauss -d golem -n wc1
+  ./app-migration-cli preflight -s gauss -d golem -n wc1 -o org-foobar
   `
 )
 
@@ -72,6 +78,15 @@ func New(config Config) (*Command, error) {
 	newCommand.mainCommand.Flags().StringVarP(&flags.srcMC, "source", "s", "", "Name of the source MC")
 	newCommand.mainCommand.Flags().StringVarP(&flags.dstMC, "destination", "d", "", "Name of the destination MC")
 	newCommand.mainCommand.Flags().StringVarP(&flags.wcName, "wc-name", "n", "", "Name of the WC to migrate")
+	newCommand.mainCommand.Flags().StringVar(&flags.filterRules, "filter-rules", "", "Rules file deciding which apps are migrated, the built-in rules are used if not set")
+	newCommand.mainCommand.Flags().StringVar(&flags.reportFile, "report-file", "", "Write the report of migrated and skipped apps as JSON to this file")
+	newCommand.mainCommand.Flags().StringVar(&flags.mode, "mode", string(cluster.ModeVintage), "Kind of the source cluster, vintage apps live in the WC namespace, capi apps live in the org namespace")
+	newCommand.mainCommand.Flags().StringVarP(&flags.orgNamespace, "org-namespace", "o", "", "Namespace of organization in capi, eg. org-foobar. Required in capi mode, the destination MC is only checked if set")
+	newCommand.mainCommand.Flags().StringVar(&flags.upgradeRules, "upgrade-rules", "", "Rules file changing the version and catalog of apps which do not run on CAPI")
+	newCommand.mainCommand.Flags().StringVar(&flags.dstWcName, "destination-wc-name", "", "Name of the WC on the destination MC if it is renamed during the migration")
+
+	flags.connection.AddSourceFlags(newCommand.mainCommand.Flags())
+	flags.connection.AddDestinationFlags(newCommand.mainCommand.Flags())
 
 	return newCommand, nil
 }
@@ -81,15 +96,21 @@ func (c *Command) CobraCommand() *cobra.Command {
 }
 
 func (c *Command) Execute(cmd *cobra.Command, args []string) error {
+	result := output.NewResult(CommandUse)
 
 	err := flags.Validate()
 	if err != nil {
-		return microerror.Mask(err)
+		return microerror.Mask(output.Write(result, err))
 	}
 
 	//c.logger = debug.MustWrapDebugLogger(c.logger, "error")
 
-	err = c.execute()
+	err = c.execute(result)
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = output.Write(result, nil)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -97,14 +118,39 @@ func (c *Command) Execute(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func (c *Command) execute() error {
+func (c *Command) execute(result *output.Result) error {
+	// the default rules depend on the mode and are chosen by GetAppCRs
+	var rules *apps.Rules
+	if flags.filterRules != "" {
+		var err error
+		rules, err = apps.LoadRules(flags.filterRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	var upgradeRules *cluster.UpgradeRules
+	if flags.upgradeRules != "" {
+		var err error
+		upgradeRules, err = cluster.LoadUpgradeRules(flags.upgradeRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
 	color.Yellow("Validating access to both MCs for app migration: %s/%s -> %s\n", flags.srcMC, flags.wcName, flags.dstMC)
 
-	mcs, err := cluster.Login(flags.srcMC, flags.dstMC)
+	mcs, err := cluster.Login(flags.connection.Source(flags.srcMC), flags.connection.Destination(flags.dstMC))
 	if err != nil {
 		return microerror.Mask(err)
 	}
+	result.AddConnections(mcs)
 	mcs.WcName = flags.wcName
+	mcs.SrcMC.Namespace = flags.wcName
+	mcs.OrgNamespace = flags.orgNamespace
+	mcs.Mode = cluster.Mode(flags.mode)
+	mcs.DstWcName = flags.dstWcName
+	mcs.UpgradeRules = upgradeRules
 
 	color.Green("Access to both MCs validated")
 
@@ -113,12 +159,50 @@ func (c *Command) execute() error {
 		return microerror.Mask(err)
 	}
 	color.Green("WorkloadCluster State is healthy: %s", health)
+	result.Health = health
+
+	migratedApps, skippedApps, err := mcs.GetAppCRs(rules)
+	if err != nil && !errors.Is(err, apps.EmptyAppsError) {
+		return microerror.Mask(err)
+	}
+
+	mcs.Apps = migratedApps
+	report := apps.NewReport(mcs.WcName, migratedApps, skippedApps)
+	mcs.ReportUpgrades(report)
+	report.Print(output.HumanWriter())
+	result.Apps = report
+
+	if flags.reportFile != "" {
+		err := report.WriteFile(flags.reportFile)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		_, _ = fmt.Fprintf(output.HumanWriter(), "Report of migrated and skipped apps written to %s\n", flags.reportFile)
+		result.Files = append(result.Files, flags.reportFile)
+	}
+
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	color.Yellow(". Found %d apps for migration", len(migratedApps))
+
+	if mcs.OrgNamespace == "" {
+		color.Yellow("Destination MC %s not checked, --org-namespace is not set", mcs.DstMC.Name)
+		result.AddWarning("Destination MC not checked, --org-namespace is not set")
+		return nil
+	}
+
+	checks, err := mcs.CheckDestination()
+	mcs.PrintPrerequisiteChecks(checks)
+	result.Checks = checks
+	for _, warning := range mcs.Warnings {
+		result.AddWarning(warning)
+	}
 
-	apps, err := apps.GetAppCRs(mcs.SrcMC.KubernetesClient, mcs.WcName)
 	if err != nil {
 		return microerror.Mask(err)
 	}
-	color.Yellow(". Found %d apps for migration", len(apps))
+	color.Green("Destination MC %s is ready for the migration", mcs.DstMC.Name)
 
 	return nil
 }
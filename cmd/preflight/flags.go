@@ -1,14 +1,28 @@
 package preflight
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/cmd/connection"
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
 )
 
 // Flags represents all the flags that can be set via the command line
 type Flags struct {
-	srcMC  string
-	dstMC  string
-	wcName string
+	srcMC        string
+	dstMC        string
+	wcName       string
+	filterRules  string
+	reportFile   string
+	mode         string
+	orgNamespace string
+	dstWcName    string
+	upgradeRules string
+
+	connection connection.Flags
 }
 
 func (f *Flags) Validate() error {
@@ -24,5 +38,18 @@ func (f *Flags) Validate() error {
 		return microerror.Maskf(invalidFlagsError, "WorkloadClusterName must not be empty")
 	}
 
+	if !slices.Contains(cluster.Modes, f.mode) {
+		return microerror.Maskf(invalidFlagsError, "Mode must be one of %s", strings.Join(cluster.Modes, ", "))
+	}
+
+	if f.mode == string(cluster.ModeCAPI) && f.orgNamespace == "" {
+		return microerror.Maskf(invalidFlagsError, "OrgNamespace must not be empty in capi mode")
+	}
+
+	err := f.connection.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
 	return nil
 }
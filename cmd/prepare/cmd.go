@@ -2,6 +2,7 @@ package prepare
 
 import (
 	"errors"
+	"fmt"
 	"os"
 
 	//	"github.com/fatih/color"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/giantswarm/app-migration-cli/pkg/apps"
 	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -34,6 +36,10 @@ const (
   Run a migration from gauss to golem:
 
   ./app-migration-cli prepare -s gauss -d golem -n wc1 -o org-foobar
+
+  Run it without access to gauss from yaml exported earlier:
+
+  ./app-migration-cli prepare -s gauss -d golem -n wc1 -o org-foobar --from-snapshot ./gauss-wc1
   `
 )
 
@@ -80,6 +86,19 @@ func New(config Config) (*Command, error) {
 	newCommand.mainCommand.Flags().StringVarP(&flags.orgNamespace, "org-namespace", "o", "", "Namespace of organization in capi, eg. org-foobar")
 	newCommand.mainCommand.Flags().StringVarP(&flags.dumpFile, "output-file", "f", "", "Name of the file where the app/cm dump will be stored")
 	newCommand.mainCommand.Flags().BoolVarP(&flags.finalizer, "finalizer", "z", false, "Apply finalizers to the source namespace. Setting this might result in the deletion of the ns during the infrastructre migration")
+	newCommand.mainCommand.Flags().StringVar(&flags.encryptionKey, "encryption-key", "", "age key file (see age-keygen) used to encrypt secrets in the dump file")
+	newCommand.mainCommand.Flags().StringVar(&flags.filterRules, "filter-rules", "", "Rules file deciding which apps are migrated, the built-in rules are used if not set")
+	newCommand.mainCommand.Flags().StringVar(&flags.metadataRules, "metadata-rules", "", "Rules file rewriting the labels and annotations of the migrated objects, the built-in rules are used if not set")
+	newCommand.mainCommand.Flags().StringVar(&flags.upgradeRules, "upgrade-rules", "", "Rules file changing the version and catalog of apps which do not run on CAPI")
+	newCommand.mainCommand.Flags().StringVar(&flags.valuesRules, "values-rules", "", "Rules file transforming the values of the migrated config maps and secrets")
+	newCommand.mainCommand.Flags().StringVar(&flags.fromSnapshot, "from-snapshot", "", "Read the apps and their config from a directory of exported yaml instead of the source MC")
+	newCommand.mainCommand.Flags().BoolVar(&flags.report, "report", false, "Write the report of migrated and skipped apps as JSON next to the dump file")
+	newCommand.mainCommand.Flags().StringVar(&flags.mode, "mode", string(cluster.ModeVintage), "Kind of the source cluster, vintage apps live in the WC namespace and are prefixed with the WC name, capi apps live in the org namespace and keep their name")
+	newCommand.mainCommand.Flags().StringVar(&flags.srcWcName, "source-wc-name", "", "Name of the WC on the source MC, same as --wc-name")
+	newCommand.mainCommand.Flags().StringVar(&flags.dstWcName, "destination-wc-name", "", "Name of the WC on the destination MC if it is renamed during the migration")
+
+	flags.connection.AddSourceFlags(newCommand.mainCommand.Flags())
+	flags.connection.AddDestinationFlags(newCommand.mainCommand.Flags())
 
 	return newCommand, nil
 }
@@ -89,15 +108,21 @@ func (c *Command) CobraCommand() *cobra.Command {
 }
 
 func (c *Command) Execute(cmd *cobra.Command, args []string) error {
+	result := output.NewResult(CommandUse)
 
 	err := flags.Validate()
 	if err != nil {
-		return microerror.Mask(err)
+		return microerror.Mask(output.Write(result, err))
 	}
 
 	//c.logger = debug.MustWrapDebugLogger(c.logger, "error")
 
-	err = c.execute()
+	err = c.execute(result)
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = output.Write(result, nil)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -105,16 +130,82 @@ func (c *Command) Execute(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func (c *Command) execute() error {
-	mcs, err := cluster.Login(flags.srcMC, flags.dstMC)
-	if err != nil {
-		return microerror.Mask(err)
+func (c *Command) execute(result *output.Result) error {
+	// the default rules depend on the mode and are chosen by GetAppCRs
+	var rules *apps.Rules
+	if flags.filterRules != "" {
+		var err error
+		rules, err = apps.LoadRules(flags.filterRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	var metadataRules *cluster.MetadataRules
+	if flags.metadataRules != "" {
+		var err error
+		metadataRules, err = cluster.LoadMetadataRules(flags.metadataRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	var upgradeRules *cluster.UpgradeRules
+	if flags.upgradeRules != "" {
+		var err error
+		upgradeRules, err = cluster.LoadUpgradeRules(flags.upgradeRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	var valuesRules *cluster.ValuesRules
+	if flags.valuesRules != "" {
+		var err error
+		valuesRules, err = cluster.LoadValuesRules(flags.valuesRules)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	var mcs *cluster.Cluster
+	var err error
+	if flags.fromSnapshot != "" {
+		mcs, err = cluster.NewFromSnapshot(flags.srcMC, flags.dstMC, flags.fromSnapshot)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		mcs.Out = output.HumanWriter()
+		color.Yellow("Reading apps of %s from snapshot %s, the source MC is not accessed", flags.srcMC, flags.fromSnapshot)
+	} else {
+		mcs, err = cluster.Login(flags.connection.Source(flags.srcMC), flags.connection.Destination(flags.dstMC))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		result.AddConnections(mcs)
 	}
 	mcs.WcName = flags.wcName
 	mcs.SrcMC.Namespace = flags.wcName
 	mcs.OrgNamespace = flags.orgNamespace
+	mcs.Mode = cluster.Mode(flags.mode)
+	mcs.DstWcName = flags.dstWcName
+	mcs.MetadataRules = metadataRules
+	mcs.UpgradeRules = upgradeRules
+	mcs.ValuesRules = valuesRules
+
+	if flags.encryptionKey != "" {
+		mcs.EncryptionKey, err = cluster.LoadEncryptionKey(flags.encryptionKey)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
 
-	f, err := os.OpenFile(mcs.AppYamlFile(flags.dumpFile), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	dumpFile, err := mcs.AppYamlFile(flags.dumpFile)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	f, err := os.OpenFile(dumpFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -128,13 +219,35 @@ func (c *Command) execute() error {
 		color.Yellow("Finalizer set on NS: %s-%s", mcs.SrcMC.Name, mcs.SrcMC.Namespace)
 	}
 
-	mcs.Apps, err = apps.GetAppCRs(mcs.SrcMC.KubernetesClient, mcs.WcName)
+	var skippedApps []apps.SkippedApp
+	mcs.Apps, skippedApps, err = mcs.GetAppCRs(rules)
+	if err != nil && !errors.Is(err, apps.EmptyAppsError) {
+		return microerror.Mask(err)
+	}
+
+	report := apps.NewReport(mcs.WcName, mcs.Apps, skippedApps)
+	mcs.ReportUpgrades(report)
+	report.Print(output.HumanWriter())
+	result.Apps = report
+
+	if flags.report {
+		reportFile := apps.ReportFileName(dumpFile)
+		err := report.WriteFile(reportFile)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		_, _ = fmt.Fprintf(output.HumanWriter(), "Report of migrated and skipped apps written to %s\n", reportFile)
+		result.Files = append(result.Files, reportFile)
+	}
+
 	if err != nil {
 		if errors.Is(err, apps.EmptyAppsError) {
 			color.Red("⚠  Warning")
 			color.Red("⚠  No apps targeted for migration")
 			color.Red("⚠  The capi-migration will continue but no apps.application.giantswarm.io CRs will be transferred")
 			color.Red("⚠  Warning")
+			result.AddWarning("No apps targeted for migration, no apps.application.giantswarm.io CRs will be transferred")
+			result.Files = append(result.Files, dumpFile)
 
 			if err := f.Close(); err != nil {
 				return microerror.Mask(err)
@@ -146,12 +259,25 @@ func (c *Command) execute() error {
 		return microerror.Mask(err)
 	}
 
+	if mcs.DstMC.KubernetesClient != nil {
+		err = mcs.WarnAppVersions()
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	} else {
+		color.Yellow("App versions are not checked, the destination MC %s is not accessed", mcs.DstMC.Name)
+	}
+
 	err = mcs.DumpApps(f)
 	if err != nil {
 		return microerror.Mask(err)
 	}
+	for _, warning := range mcs.Warnings {
+		result.AddWarning(warning)
+	}
 
-	color.Green("Apps (%d) and config is dumped and migrated to disk: %s", len(mcs.Apps), mcs.AppYamlFile(flags.dumpFile))
+	color.Green("Apps (%d) and config is dumped and migrated to disk: %s", len(mcs.Apps), dumpFile)
+	result.Files = append(result.Files, dumpFile)
 
 	if err := f.Close(); err != nil {
 		return microerror.Mask(err)
@@ -1,17 +1,35 @@
 package prepare
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/cmd/connection"
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
 )
 
 // Flags represents all the flags that can be set via the command line
 type Flags struct {
-	srcMC        string
-	dstMC        string
-	wcName       string
-	finalizer    bool
-	orgNamespace string
-	dumpFile     string
+	srcMC         string
+	dstMC         string
+	wcName        string
+	srcWcName     string
+	finalizer     bool
+	orgNamespace  string
+	dumpFile      string
+	encryptionKey string
+	filterRules   string
+	metadataRules string
+	upgradeRules  string
+	valuesRules   string
+	fromSnapshot  string
+	report        bool
+	mode          string
+	dstWcName     string
+
+	connection connection.Flags
 }
 
 func (f *Flags) Validate() error {
@@ -23,6 +41,13 @@ func (f *Flags) Validate() error {
 		return microerror.Maskf(invalidFlagsError, "DestinationMC must not be empty")
 	}
 
+	if f.srcWcName != "" {
+		if f.wcName != "" && f.wcName != f.srcWcName {
+			return microerror.Maskf(invalidFlagsError, "WorkloadClusterName and SourceWorkloadClusterName must not differ")
+		}
+		f.wcName = f.srcWcName
+	}
+
 	if f.wcName == "" {
 		return microerror.Maskf(invalidFlagsError, "WorkloadClusterName must not be empty")
 	}
@@ -31,5 +56,22 @@ func (f *Flags) Validate() error {
 		return microerror.Maskf(invalidFlagsError, "OrgNamespace must not be empty")
 	}
 
+	if f.fromSnapshot != "" && f.finalizer {
+		return microerror.Maskf(invalidFlagsError, "Finalizer can not be set when preparing from a snapshot")
+	}
+
+	if !slices.Contains(cluster.Modes, f.mode) {
+		return microerror.Maskf(invalidFlagsError, "Mode must be one of %s", strings.Join(cluster.Modes, ", "))
+	}
+
+	if f.mode == string(cluster.ModeCAPI) && f.finalizer {
+		return microerror.Maskf(invalidFlagsError, "Finalizer can not be set on the org namespace of a CAPI cluster")
+	}
+
+	err := f.connection.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
 	return nil
 }
@@ -0,0 +1,126 @@
+package restore
+
+import (
+	"github.com/fatih/color"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
+)
+
+var (
+	flags = &Flags{}
+)
+
+const (
+	// CommandUse indicates the general syntax of the command
+	CommandUse = "restore"
+
+	// CommandShort describes the command in a short list
+	CommandShort = "Restore a snapshot to the source MC"
+
+	// CommandLong documents the command in full length
+	CommandLong = `Apply every object of a snapshot written by the snapshot command to the
+  source MC again. Config is restored before the apps, objects which still exist are
+  overwritten with their state in the snapshot.
+
+  Restore the backup of wc1 to gauss:
+
+  ./app-migration-cli restore -s gauss -f gauss-wc1-snapshot.tar.gz
+  `
+)
+
+// Config represents the configuration used to create a new command.
+type Config struct {
+	// Settings.
+	MainCommand *cobra.Command
+	Logger      micrologger.Logger
+}
+
+type Command struct {
+	// Dependencies.
+	logger micrologger.Logger
+
+	// Settings.
+	mainCommand *cobra.Command
+}
+
+// New creates a new configured command.
+func New(config Config) (*Command, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	newCommand := &Command{
+		// Dependencies.
+		logger: config.Logger,
+
+		// Internals.
+		mainCommand: nil,
+	}
+
+	newCommand.mainCommand = &cobra.Command{
+		Use:   CommandUse,
+		Short: CommandShort,
+		Long:  CommandLong,
+		RunE:  newCommand.Execute,
+	}
+
+	newCommand.mainCommand.Flags().StringVarP(&flags.srcMC, "source", "s", "", "Name of the source MC")
+	newCommand.mainCommand.Flags().StringVarP(&flags.snapshot, "snapshot", "f", "", "Directory or .tar.gz archive written by the snapshot command")
+
+	flags.connection.AddSourceFlags(newCommand.mainCommand.Flags())
+
+	return newCommand, nil
+}
+
+func (c *Command) CobraCommand() *cobra.Command {
+	return c.mainCommand
+}
+
+func (c *Command) Execute(cmd *cobra.Command, args []string) error {
+	result := output.NewResult(CommandUse)
+
+	err := flags.Validate()
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = c.execute(result)
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = output.Write(result, nil)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *Command) execute(result *output.Result) error {
+	srcMC, err := cluster.LoginMC(flags.connection.Source(flags.srcMC))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	mcs := &cluster.Cluster{
+		SrcMC: srcMC,
+		Out:   output.HumanWriter(),
+	}
+	result.AddConnections(mcs)
+
+	restored, err := mcs.RestoreSnapshot(flags.snapshot)
+	result.Objects = restored
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	color.Green("%d objects of %s restored to %s", len(restored), flags.snapshot, flags.srcMC)
+
+	return nil
+}
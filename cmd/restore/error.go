@@ -0,0 +1,13 @@
+package restore
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidFlagsError = &microerror.Error{
+	Kind: "invalidFlagsError",
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
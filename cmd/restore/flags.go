@@ -0,0 +1,32 @@
+package restore
+
+import (
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/cmd/connection"
+)
+
+// Flags represents all the flags that can be set via the command line
+type Flags struct {
+	srcMC    string
+	snapshot string
+
+	connection connection.Flags
+}
+
+func (f *Flags) Validate() error {
+	if f.srcMC == "" {
+		return microerror.Maskf(invalidFlagsError, "SourceMC must not be empty")
+	}
+
+	if f.snapshot == "" {
+		return microerror.Maskf(invalidFlagsError, "Snapshot must not be empty")
+	}
+
+	err := f.connection.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
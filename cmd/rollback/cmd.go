@@ -0,0 +1,154 @@
+package rollback
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fatih/color"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
+)
+
+var (
+	flags = &Flags{}
+)
+
+const (
+	// CommandUse indicates the general syntax of the command
+	CommandUse = "rollback"
+
+	// CommandShort describes the command in a short list
+	CommandShort = "Remove everything the apply stage created"
+
+	// CommandLong documents the command in full length
+	CommandLong = `In the rollback phase the apps and additional config read from
+  disk are deleted from the destination MC again. Apps are deleted first, then their config.
+  Objects which were not created by apply are left untouched.
+
+  List what a rollback of a migration from gauss to golem would delete:
+
+  ./app-migration-cli rollback -f test25-apps.yaml -s gauss -d golem -n wc1 -o org-foobar --dry-run
+  `
+)
+
+// Config represents the configuration used to create a new command.
+type Config struct {
+	// Settings.
+	MainCommand *cobra.Command
+	Logger      micrologger.Logger
+}
+
+type Command struct {
+	// Dependencies.
+	logger micrologger.Logger
+
+	// Settings.
+	mainCommand *cobra.Command
+}
+
+// New creates a new configured command.
+func New(config Config) (*Command, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	newCommand := &Command{
+		// Dependencies.
+		logger: config.Logger,
+
+		// Internals.
+		mainCommand: nil,
+	}
+
+	newCommand.mainCommand = &cobra.Command{
+		Use:   CommandUse,
+		Short: CommandShort,
+		Long:  CommandLong,
+		RunE:  newCommand.Execute,
+	}
+
+	newCommand.mainCommand.Flags().StringVarP(&flags.sourceFile, "dump-file", "f", "", "Filename that contains the yaml-resources for migration")
+	newCommand.mainCommand.Flags().StringVarP(&flags.dstMC, "destination", "d", "", "Name of the destination MC")
+	newCommand.mainCommand.Flags().StringVarP(&flags.srcMC, "source", "s", "", "Name of the source MC")
+	newCommand.mainCommand.Flags().StringVarP(&flags.wcName, "wc-name", "n", "", "Name of the source WC, the destination WC name is read from the dump file")
+	newCommand.mainCommand.Flags().StringVarP(&flags.orgNamespace, "org-namespace", "o", "", "Namespace of organization in capi, eg. org-foobar")
+	newCommand.mainCommand.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Only list the objects which would be deleted")
+
+	flags.connection.AddSourceFlags(newCommand.mainCommand.Flags())
+	flags.connection.AddDestinationFlags(newCommand.mainCommand.Flags())
+
+	return newCommand, nil
+}
+
+func (c *Command) CobraCommand() *cobra.Command {
+	return c.mainCommand
+}
+
+func (c *Command) Execute(cmd *cobra.Command, args []string) error {
+	result := output.NewResult(CommandUse)
+
+	err := flags.Validate()
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = c.execute(cmd.Context(), result)
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = output.Write(result, nil)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *Command) execute(ctx context.Context, result *output.Result) error {
+	mcs, err := cluster.Login(flags.connection.Source(flags.srcMC), flags.connection.Destination(flags.dstMC))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	result.AddConnections(mcs)
+	mcs.WcName = flags.wcName
+	mcs.SrcMC.Namespace = flags.wcName
+	mcs.OrgNamespace = flags.orgNamespace
+
+	rolledBack, err := mcs.RollbackCAPIApps(ctx, flags.sourceFile, flags.dryRun)
+	if errors.Is(err, cluster.MigrationFileEmpty) {
+		color.Red("⚠  Warning")
+		color.Red("⚠  No apps targeted for migration")
+		color.Red("⚠  The given file was empty")
+		color.Red("⚠  Warning")
+		result.AddWarning("No apps targeted for migration, the given file was empty")
+
+		return nil
+	}
+
+	result.Objects = rolledBack
+
+	for _, obj := range rolledBack {
+		switch obj.Result {
+		case cluster.RollbackResultSkipped:
+			color.Yellow("%s/%s/%s %s", obj.Kind, obj.Namespace, obj.Name, obj.Result)
+		default:
+			color.Green("%s/%s/%s %s", obj.Kind, obj.Namespace, obj.Name, obj.Result)
+		}
+	}
+
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if flags.dryRun {
+		color.Yellow("Dry-run, nothing was deleted")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package rollback
+
+import (
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/cmd/connection"
+)
+
+// Flags represents all the flags that can be set via the command line
+type Flags struct {
+	sourceFile   string
+	dstMC        string
+	srcMC        string
+	wcName       string
+	orgNamespace string
+	dryRun       bool
+
+	connection connection.Flags
+}
+
+func (f *Flags) Validate() error {
+	if f.srcMC == "" {
+		return microerror.Maskf(invalidFlagsError, "SourceMC must not be empty")
+	}
+
+	if f.dstMC == "" {
+		return microerror.Maskf(invalidFlagsError, "DestinationMC must not be empty")
+	}
+
+	if f.wcName == "" {
+		return microerror.Maskf(invalidFlagsError, "WorkloadClusterName must not be empty")
+	}
+
+	if f.orgNamespace == "" {
+		return microerror.Maskf(invalidFlagsError, "OrgNamespace must not be empty")
+	}
+
+	err := f.connection.Validate()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
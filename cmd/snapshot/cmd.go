@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
+)
+
+var (
+	flags = &Flags{}
+)
+
+const (
+	// CommandUse indicates the general syntax of the command
+	CommandUse = "snapshot"
+
+	// CommandShort describes the command in a short list
+	CommandShort = "Back up the apps of a WC on the source MC"
+
+	// CommandLong documents the command in full length
+	CommandLong = `Write every App, ConfigMap and Secret of the WC namespace on the source MC,
+  and the config referenced by the apps in other namespaces, to a directory or a .tar.gz
+  archive. The objects are not transformed, so they can be restored to the source MC
+  with the restore command or read by prepare --from-snapshot.
+
+  Back up wc1 on gauss before the infrastructure migration:
+
+  ./app-migration-cli snapshot -s gauss -n wc1 -f gauss-wc1-snapshot.tar.gz
+  `
+)
+
+// Config represents the configuration used to create a new command.
+type Config struct {
+	// Settings.
+	MainCommand *cobra.Command
+	Logger      micrologger.Logger
+}
+
+type Command struct {
+	// Dependencies.
+	logger micrologger.Logger
+
+	// Settings.
+	mainCommand *cobra.Command
+}
+
+// New creates a new configured command.
+func New(config Config) (*Command, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	newCommand := &Command{
+		// Dependencies.
+		logger: config.Logger,
+
+		// Internals.
+		mainCommand: nil,
+	}
+
+	newCommand.mainCommand = &cobra.Command{
+		Use:   CommandUse,
+		Short: CommandShort,
+		Long:  CommandLong,
+		RunE:  newCommand.Execute,
+	}
+
+	newCommand.mainCommand.Flags().StringVarP(&flags.srcMC, "source", "s", "", "Name of the source MC")
+	newCommand.mainCommand.Flags().StringVarP(&flags.wcName, "wc-name", "n", "", "Name of the WC to back up")
+	newCommand.mainCommand.Flags().StringVarP(&flags.snapshot, "snapshot", "f", "", "Directory or .tar.gz archive the snapshot is written to, defaults to <source>-<wc-name>-snapshot")
+
+	flags.connection.AddSourceFlags(newCommand.mainCommand.Flags())
+
+	return newCommand, nil
+}
+
+func (c *Command) CobraCommand() *cobra.Command {
+	return c.mainCommand
+}
+
+func (c *Command) Execute(cmd *cobra.Command, args []string) error {
+	result := output.NewResult(CommandUse)
+
+	err := flags.Validate()
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = c.execute(result)
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = output.Write(result, nil)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *Command) execute(result *output.Result) error {
+	srcMC, err := cluster.LoginMC(flags.connection.Source(flags.srcMC))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	srcMC.Namespace = flags.wcName
+
+	mcs := &cluster.Cluster{
+		WcName: flags.wcName,
+		SrcMC:  srcMC,
+		Out:    output.HumanWriter(),
+	}
+	result.AddConnections(mcs)
+
+	path := flags.snapshot
+	if path == "" {
+		path = fmt.Sprintf("%s-%s-snapshot", flags.srcMC, flags.wcName)
+	}
+
+	objects, err := mcs.SnapshotWC(path)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	result.Objects = objects
+	result.Files = append(result.Files, path)
+
+	for _, obj := range objects {
+		_, _ = fmt.Fprintf(output.HumanWriter(), "%s/%s/%s\n", obj.Kind, obj.Namespace, obj.Name)
+	}
+
+	color.Green("Snapshot of %d objects written to %s", len(objects), path)
+	color.Yellow("The snapshot contains secrets in plain text, store it safely")
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/giantswarm/backoff"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+	"github.com/giantswarm/app-migration-cli/pkg/output"
+)
+
+var (
+	flags = &Flags{}
+)
+
+const (
+	// CommandUse indicates the general syntax of the command
+	CommandUse = "verify"
+
+	// CommandShort describes the command in a short list
+	CommandShort = "Wait for the migrated apps to be deployed"
+
+	// CommandLong documents the command in full length
+	CommandLong = `Poll every app from disk on the destination MC until its release
+  is deployed. Fails if an app failed to deploy or the timeout is reached. It operates read-only.
+
+  Verify a migration from gauss to golem:
+
+  ./app-migration-cli verify -f test25-apps.yaml -s gauss -d golem -n wc1 -o org-foobar
+  `
+)
+
+// Config represents the configuration used to create a new command.
+type Config struct {
+	// Settings.
+	MainCommand *cobra.Command
+	Logger      micrologger.Logger
+}
+
+type Command struct {
+	// Dependencies.
+	logger micrologger.Logger
+
+	// Settings.
+	mainCommand *cobra.Command
+}
+
+// New creates a new configured command.
+func New(config Config) (*Command, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	newCommand := &Command{
+		// Dependencies.
+		logger: config.Logger,
+
+		// Internals.
+		mainCommand: nil,
+	}
+
+	newCommand.mainCommand = &cobra.Command{
+		Use:   CommandUse,
+		Short: CommandShort,
+		Long:  CommandLong,
+		RunE:  newCommand.Execute,
+	}
+
+	newCommand.mainCommand.Flags().StringVarP(&flags.sourceFile, "dump-file", "f", "", "Filename that contains the yaml-resources for migration")
+	newCommand.mainCommand.Flags().StringVarP(&flags.dstMC, "destination", "d", "", "Name of the destination MC")
+	newCommand.mainCommand.Flags().StringVarP(&flags.srcMC, "source", "s", "", "Name of the source MC")
+	newCommand.mainCommand.Flags().StringVarP(&flags.wcName, "wc-name", "n", "", "Name of the source WC, the destination WC name is read from the dump file")
+	newCommand.mainCommand.Flags().StringVarP(&flags.orgNamespace, "org-namespace", "o", "", "Namespace of organization in capi, eg. org-foobar")
+	newCommand.mainCommand.Flags().StringVar(&flags.encryptionKey, "encryption-key", "", "age key file of the dump file, not used")
+	newCommand.mainCommand.Flags().DurationVar(&flags.timeout, "timeout", 10*time.Minute, "Time to wait for all apps to be deployed")
+
+	flags.connection.AddSourceFlags(newCommand.mainCommand.Flags())
+	flags.connection.AddDestinationFlags(newCommand.mainCommand.Flags())
+
+	// verify only reads the apps of the dump file, --encryption-key is
+	// accepted for existing scripts
+	_ = newCommand.mainCommand.Flags().MarkDeprecated("encryption-key", "verify does not read secrets of the dump file")
+
+	return newCommand, nil
+}
+
+func (c *Command) CobraCommand() *cobra.Command {
+	return c.mainCommand
+}
+
+func (c *Command) Execute(cmd *cobra.Command, args []string) error {
+	result := output.NewResult(CommandUse)
+
+	err := flags.Validate()
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = c.execute(cmd.Context(), result)
+	if err != nil {
+		return microerror.Mask(output.Write(result, err))
+	}
+
+	err = output.Write(result, nil)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *Command) execute(ctx context.Context, result *output.Result) error {
+	mcs, err := cluster.Login(flags.connection.Source(flags.srcMC), flags.connection.Destination(flags.dstMC))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	result.AddConnections(mcs)
+	mcs.WcName = flags.wcName
+	mcs.SrcMC.Namespace = flags.wcName
+	mcs.OrgNamespace = flags.orgNamespace
+	mcs.BackOff = backoff.NewConstant(flags.timeout, 10*time.Second)
+
+	verifications, err := mcs.VerifyCAPIApps(ctx, flags.sourceFile)
+	if errors.Is(err, cluster.MigrationFileEmpty) {
+		color.Red("⚠  Warning")
+		color.Red("⚠  No apps targeted for migration")
+		color.Red("⚠  The given file was empty")
+		color.Red("⚠  Warning")
+		result.AddWarning("No apps targeted for migration, the given file was empty")
+
+		return nil
+	}
+
+	mcs.PrintAppVerifications(verifications)
+	result.Verifications = verifications
+
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	color.Green("All migrated apps are deployed.")
+
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -12,7 +14,18 @@ import (
 )
 
 func main() {
-	err := mainE(context.Background())
+	// cancel running commands on Ctrl-C, eg. waiting for prerequisites
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	// restore the default signal handling after the first signal, so a
+	// second Ctrl-C kills commands which do not stop in time
+	go func() {
+		<-ctx.Done()
+		cancel()
+	}()
+
+	err := mainE(ctx)
+	cancel()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n\nTo increase verbosity, re-run with --level=debug\n", microerror.Pretty(err, true))
 		os.Exit(2)
@@ -52,7 +65,7 @@ func mainE(ctx context.Context) error {
 	newCommand.CobraCommand().SilenceUsage = true
 	newCommand.CobraCommand().CompletionOptions.DisableDefaultCmd = true
 
-	err = newCommand.CobraCommand().Execute()
+	err = newCommand.CobraCommand().ExecuteContext(ctx)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -2,8 +2,6 @@ package apps
 
 import (
 	"context"
-	"slices"
-	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -11,7 +9,12 @@ import (
 	"github.com/giantswarm/microerror"
 )
 
-func GetAppCRs(k8sClient client.Client, clusterName string) ([]app.App, error) {
+// ClusterLabel is set on the apps of a workload cluster.
+const ClusterLabel = "giantswarm.io/cluster"
+
+// GetAppCRs returns the apps of the cluster which should be migrated and the
+// ones skipped by the rules. DefaultRules are used if rules is nil.
+func GetAppCRs(k8sClient client.Client, clusterName string, rules *Rules) ([]app.App, []SkippedApp, error) {
 	objList := &app.AppList{}
 
 	// todo: not possible to filter on "spec.catalog" bc/ cached list not indexed?
@@ -19,48 +22,48 @@ func GetAppCRs(k8sClient client.Client, clusterName string) ([]app.App, error) {
 	//selector := client.MatchingLabels{"app.kubernetes.io/name"
 	err := k8sClient.List(context.TODO(), objList, selector)
 	if err != nil {
-		return nil, microerror.Mask(err)
+		return nil, nil, microerror.Mask(err)
 	}
 
-	filteredApps, err := filterAppCRs(objList.Items)
+	if rules == nil {
+		rules = DefaultRules()
+	}
 
-	return filteredApps, err
+	return filterAppCRsWithRules(objList.Items, rules)
 }
 
-// blacklist certain apps for migration
-func filterAppCRs(allApps []app.App) ([]app.App, error) {
-	var filteredApps []app.App
-appLoop:
-	for _, application := range allApps {
-		// skip "default" apps; these should be installed by default on the MC
-		if application.Spec.Catalog == "default" {
-			continue
-		}
+// GetCAPIAppCRs returns the apps of a CAPI cluster like GetAppCRs. They live
+// in the org namespace next to the apps of other clusters and are selected by
+// the cluster label or their kubeconfig secret. DefaultCAPIRules are used if
+// rules is nil.
+func GetCAPIAppCRs(k8sClient client.Client, clusterName string, orgNamespace string, rules *Rules) ([]app.App, []SkippedApp, error) {
+	objList := &app.AppList{}
 
-		// skip bundled apps as we only migrate their parent
-		// todo: verify thats formally correct
-		labels := application.GetLabels()
-		for key, value := range labels {
-			if strings.Contains(key, "giantswarm.io/managed-by") && (strings.Contains(value, "bundle") || strings.Contains(value, "operator")) {
-				// we skip this app completly
-				continue appLoop
-			}
-		}
+	selector := client.MatchingFields{"metadata.namespace": orgNamespace}
+	err := k8sClient.List(context.TODO(), objList, selector)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
 
-		// skip specific apps that are no longer supported on CAPI
-		if slices.Contains([]string{
-			"k8s-initiator-app",
-			"k8s-initiator-app-cgroupsv1",
-		}, application.Spec.Name) {
-			continue
+	var clusterApps []app.App
+	for _, application := range objList.Items {
+		if application.GetLabels()[ClusterLabel] == clusterName || application.Spec.KubeConfig.Secret.Name == KubeconfigName(clusterName) {
+			clusterApps = append(clusterApps, application)
 		}
+	}
 
-		filteredApps = append(filteredApps, application)
+	if rules == nil {
+		rules = DefaultCAPIRules()
 	}
 
+	return filterAppCRsWithRules(clusterApps, rules)
+}
+
+func filterAppCRsWithRules(allApps []app.App, rules *Rules) ([]app.App, []SkippedApp, error) {
+	filteredApps, skippedApps := rules.Filter(allApps)
 	if len(filteredApps) == 0 {
-		return nil, microerror.Maskf(EmptyAppsError, "No non-default apps found for migration")
+		return nil, skippedApps, microerror.Maskf(EmptyAppsError, "No non-default apps found for migration")
 	}
 
-	return filteredApps, nil
+	return filteredApps, skippedApps, nil
 }
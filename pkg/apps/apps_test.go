@@ -5,12 +5,24 @@ import (
 	"testing"
 
 	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
+// newTestApp returns the app <name> in the namespace of the WC wc1.
+func newTestApp(name string, catalog string, labels map[string]string) app.App {
+	return app.App{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "wc1", Labels: labels},
+		Spec:       app.AppSpec{Name: name, Catalog: catalog},
+	}
+}
+
 func TestFilterAppCRsEmptyReturn(t *testing.T) {
 	emptyApp := []app.App{}
 
-	_, err := filterAppCRs(emptyApp)
+	_, _, err := filterAppCRsWithRules(emptyApp, DefaultRules())
 
 	if !errors.Is(err, EmptyAppsError) {
 		t.Fatalf("Empty App List is not returning error")
@@ -29,7 +41,7 @@ func TestFilteringOfBundledApps(t *testing.T) {
 		newApp,
 	}
 
-	_, err := filterAppCRs(appList)
+	_, _, err := filterAppCRsWithRules(appList, DefaultRules())
 	if !errors.Is(err, EmptyAppsError) {
 		t.Fatalf("App Bundle should be filtered for migration")
 	}
@@ -48,7 +60,7 @@ func TestFilteringOfBundledSecurityApps(t *testing.T) {
 		newApp,
 	}
 
-	_, err := filterAppCRs(appList)
+	_, _, err := filterAppCRsWithRules(appList, DefaultRules())
 	if !errors.Is(err, EmptyAppsError) {
 		t.Fatalf("App Bundle should be filtered for migration")
 	}
@@ -66,7 +78,7 @@ func TestNoFilteringOfCustomerApps(t *testing.T) {
 		newApp,
 	}
 
-	_, err := filterAppCRs(appList)
+	_, _, err := filterAppCRsWithRules(appList, DefaultRules())
 	if err != nil && !errors.Is(err, EmptyAppsError) {
 		t.Fatalf("App Bundle should be filtered for migration")
 	}
@@ -84,7 +96,7 @@ func TestFilteringOfAppOperatorApps(t *testing.T) {
 		newApp,
 	}
 
-	_, err := filterAppCRs(appList)
+	_, _, err := filterAppCRsWithRules(appList, DefaultRules())
 	if !errors.Is(err, EmptyAppsError) {
 		t.Fatalf("App Bundle should be filtered for migration")
 	}
@@ -98,7 +110,7 @@ func TestFilteringOfDefaultApps(t *testing.T) {
 		newApp,
 	}
 
-	_, err := filterAppCRs(appList)
+	_, _, err := filterAppCRsWithRules(appList, DefaultRules())
 	if !errors.Is(err, EmptyAppsError) {
 		t.Fatalf("Apps from the `default` catalog should be filtered")
 	}
@@ -116,9 +128,54 @@ func TestFilteringOfNamedApps(t *testing.T) {
 			newApp,
 		}
 
-		_, err := filterAppCRs(appList)
+		_, _, err := filterAppCRsWithRules(appList, DefaultRules())
 		if !errors.Is(err, EmptyAppsError) {
 			t.Fatalf("Apps named `%s` catalog should be filtered", newApp.Spec.Name)
 		}
 	}
 }
+
+func TestGetCAPIAppCRs(t *testing.T) {
+	orgNamespace := "org-foo"
+
+	newApp := func(name string, catalog string, labels map[string]string, kubeconfig string) *app.App {
+		a := newTestApp(name, catalog, labels)
+		a.Namespace = orgNamespace
+		a.Spec.KubeConfig.Secret.Name = kubeconfig
+		return &a
+	}
+
+	scheme := runtime.NewScheme()
+	_ = app.AddToScheme(scheme)
+
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newApp("wc1", "cluster", nil, ""),
+			newApp("wc1-default-apps", "cluster", map[string]string{ClusterLabel: "wc1"}, "wc1-kubeconfig"),
+			newApp("loki", "giantswarm", map[string]string{ClusterLabel: "wc1"}, "wc1-kubeconfig"),
+			newApp("unlabeled", "giantswarm", nil, "wc1-kubeconfig"),
+			newApp("other", "giantswarm", map[string]string{ClusterLabel: "wc2"}, "wc2-kubeconfig"),
+		).
+		WithIndex(&app.App{}, "metadata.namespace", func(o client.Object) []string {
+			return []string{o.GetNamespace()}
+		}).
+		Build()
+
+	migratedApps, skippedApps, err := GetCAPIAppCRs(k8sClient, "wc1", orgNamespace, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var names []string
+	for _, a := range migratedApps {
+		names = append(names, a.Name)
+	}
+	if len(names) != 2 || names[0] != "loki" || names[1] != "unlabeled" {
+		t.Fatalf("Migrated apps are wrong. Is: %v; Want: %v", names, []string{"loki", "unlabeled"})
+	}
+
+	if len(skippedApps) != 1 || skippedApps[0].App.Name != "wc1-default-apps" || skippedApps[0].Rule != "cluster-catalog" {
+		t.Fatalf("Skipped apps are wrong. Is: %v", skippedApps)
+	}
+}
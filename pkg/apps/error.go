@@ -7,3 +7,7 @@ import (
 var EmptyAppsError = &microerror.Error{
 	Kind: "emptyAppsError",
 }
+
+var invalidRulesError = &microerror.Error{
+	Kind: "invalidRulesError",
+}
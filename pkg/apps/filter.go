@@ -0,0 +1,289 @@
+package apps
+
+import (
+	"os"
+	"strings"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/labels"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	RuleActionInclude = "include"
+	RuleActionExclude = "exclude"
+)
+
+// Rules decide which apps are migrated. They are evaluated in order and the
+// first matching rule wins. Apps not matched by any rule are migrated.
+//
+//	rules:
+//	- name: keep-customer-loki
+//	  action: include
+//	  appNames: ["loki"]
+//	- name: default-catalog
+//	  action: exclude
+//	  catalogs: ["default"]
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule matches an app if all of its set criteria match. A list criterion
+// matches if any of its entries matches. Values may contain `*` wildcards,
+// which also match `/`.
+type Rule struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	// Reason is reported for every app skipped by the rule.
+	Reason string `json:"reason,omitempty"`
+
+	// Catalogs are matched against spec.catalog.
+	Catalogs []string `json:"catalogs,omitempty"`
+	// AppNames are matched against metadata.name.
+	AppNames []string `json:"appNames,omitempty"`
+	// SpecNames are matched against spec.name.
+	SpecNames []string `json:"specNames,omitempty"`
+	// Namespaces are matched against metadata.namespace.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Labels match if for every key/value pattern a matching label exists.
+	Labels map[string]string `json:"labels,omitempty"`
+	// LabelSelector is a kubernetes label selector, eg. "foo=bar,!baz".
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// SkippedApp is an app which is not migrated and the rule which excluded it.
+type SkippedApp struct {
+	App    app.App
+	Rule   string
+	Reason string
+}
+
+// DefaultRules returns the rules used for vintage clusters if no rules file is
+// given.
+func DefaultRules() *Rules {
+	return &Rules{
+		Rules: []Rule{
+			{
+				Name:     "default-catalog",
+				Action:   RuleActionExclude,
+				Reason:   "Apps from the default catalog are installed by default on the MC",
+				Catalogs: []string{"default"},
+			},
+			{
+				// todo: verify thats formally correct
+				Name:   "bundle-child",
+				Action: RuleActionExclude,
+				Reason: "Bundled apps are recreated by their migrated parent",
+				Labels: map[string]string{"*giantswarm.io/managed-by*": "*bundle*"},
+			},
+			{
+				Name:   "operator-managed",
+				Action: RuleActionExclude,
+				Reason: "Apps managed by an operator are recreated on the destination MC",
+				Labels: map[string]string{"*giantswarm.io/managed-by*": "*operator*"},
+			},
+			{
+				Name:   "unsupported-on-capi",
+				Action: RuleActionExclude,
+				Reason: "App is no longer supported on CAPI",
+				SpecNames: []string{
+					"k8s-initiator-app",
+					"k8s-initiator-app-cgroupsv1",
+				},
+			},
+		},
+	}
+}
+
+// DefaultCAPIRules returns the rules used for CAPI clusters if no rules file
+// is given. On top of DefaultRules they skip the cluster app and the default
+// apps, which are created with the cluster on the destination MC.
+func DefaultCAPIRules() *Rules {
+	rules := DefaultRules()
+
+	// keep unsupported-on-capi last
+	last := len(rules.Rules) - 1
+	capiRules := []Rule{
+		{
+			Name:     "cluster-catalog",
+			Action:   RuleActionExclude,
+			Reason:   "Cluster and default apps are created with the cluster on the destination MC",
+			Catalogs: []string{"cluster"},
+		},
+		{
+			Name:   "default-apps-managed",
+			Action: RuleActionExclude,
+			Reason: "Apps of the default apps are created with the cluster on the destination MC",
+			Labels: map[string]string{"*giantswarm.io/managed-by*": "*default-apps*"},
+		},
+	}
+	rules.Rules = append(rules.Rules[:last], append(capiRules, rules.Rules[last])...)
+
+	return rules
+}
+
+// LoadRules reads and validates a rules file. The built-in default rules are
+// not added, they can be copied into the file if needed.
+func LoadRules(filename string) (*Rules, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var rules Rules
+	err = k8syaml.UnmarshalStrict(data, &rules)
+	if err != nil {
+		return nil, microerror.Maskf(invalidRulesError, "Could not parse rules %s: %s", filename, err)
+	}
+
+	err = rules.Validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &rules, nil
+}
+
+func (r *Rules) Validate() error {
+	for i, rule := range r.Rules {
+		if rule.Name == "" {
+			return microerror.Maskf(invalidRulesError, "rules[%d].name must not be empty", i)
+		}
+
+		if rule.Action != RuleActionInclude && rule.Action != RuleActionExclude {
+			return microerror.Maskf(invalidRulesError, "rules[%d].action must be %q or %q", i, RuleActionInclude, RuleActionExclude)
+		}
+
+		if rule.LabelSelector != "" {
+			_, err := labels.Parse(rule.LabelSelector)
+			if err != nil {
+				return microerror.Maskf(invalidRulesError, "rules[%d].labelSelector is invalid: %s", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Filter splits the apps into the ones to migrate and the skipped ones.
+func (r *Rules) Filter(allApps []app.App) ([]app.App, []SkippedApp) {
+	var filteredApps []app.App
+	var skippedApps []SkippedApp
+
+	for _, application := range allApps {
+		rule := r.match(application)
+		if rule != nil && rule.Action == RuleActionExclude {
+			skippedApps = append(skippedApps, SkippedApp{
+				App:    application,
+				Rule:   rule.Name,
+				Reason: rule.Reason,
+			})
+			continue
+		}
+
+		filteredApps = append(filteredApps, application)
+	}
+
+	return filteredApps, skippedApps
+}
+
+func (r *Rules) match(application app.App) *Rule {
+	for i := range r.Rules {
+		if r.Rules[i].matches(application) {
+			return &r.Rules[i]
+		}
+	}
+
+	return nil
+}
+
+func (r *Rule) matches(application app.App) bool {
+	if len(r.Catalogs) > 0 && !MatchesAny(r.Catalogs, application.Spec.Catalog) {
+		return false
+	}
+
+	if len(r.AppNames) > 0 && !MatchesAny(r.AppNames, application.GetName()) {
+		return false
+	}
+
+	if len(r.SpecNames) > 0 && !MatchesAny(r.SpecNames, application.Spec.Name) {
+		return false
+	}
+
+	if len(r.Namespaces) > 0 && !MatchesAny(r.Namespaces, application.GetNamespace()) {
+		return false
+	}
+
+	for keyPattern, valuePattern := range r.Labels {
+		found := false
+		for key, value := range application.GetLabels() {
+			if GlobMatch(keyPattern, key) && GlobMatch(valuePattern, value) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if r.LabelSelector != "" {
+		// validated when loading the rules
+		selector, err := labels.Parse(r.LabelSelector)
+		if err != nil || !selector.Matches(labels.Set(application.GetLabels())) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchesAny tells whether value matches any of the patterns, see GlobMatch.
+func MatchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if GlobMatch(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GlobMatch matches value against a pattern where `*` matches any sequence of
+// characters, including `/` which is common in label keys. It is called for
+// every rule and object, so it matches without compiling a regexp.
+func GlobMatch(pattern string, value string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == value
+	}
+
+	// p and v are the positions in pattern and value, star the position after
+	// the last `*` seen and match the position in value it resumes from.
+	p, v := 0, 0
+	star, match := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star = p + 1
+			match = v
+			p++
+		case p < len(pattern) && pattern[p] == value[v]:
+			p++
+			v++
+		case star >= 0:
+			match++
+			p = star
+			v = match
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
@@ -0,0 +1,168 @@
+package apps
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+)
+
+func TestDefaultRulesReportSkippingRule(t *testing.T) {
+	appList := []app.App{
+		newTestApp("loki", "giantswarm", nil),
+		newTestApp("cert-exporter", "default", nil),
+		newTestApp("promtail", "giantswarm", map[string]string{"giantswarm.io/managed-by": "observability-bundle"}),
+		newTestApp("k8s-initiator-app", "giantswarm", nil),
+	}
+
+	filteredApps, skippedApps := DefaultRules().Filter(appList)
+
+	if len(filteredApps) != 1 || filteredApps[0].Name != "loki" {
+		t.Fatalf("Only loki should be migrated; Is: %v", filteredApps)
+	}
+
+	want := map[string]string{
+		"cert-exporter":     "default-catalog",
+		"promtail":          "bundle-child",
+		"k8s-initiator-app": "unsupported-on-capi",
+	}
+
+	if len(skippedApps) != len(want) {
+		t.Fatalf("Skipped apps not correct; Is: %d; Want: %d", len(skippedApps), len(want))
+	}
+
+	for _, skipped := range skippedApps {
+		if want[skipped.App.Name] != skipped.Rule {
+			t.Fatalf("Rule skipping %s not correct; Is: %s; Want: %s", skipped.App.Name, skipped.Rule, want[skipped.App.Name])
+		}
+	}
+}
+
+func TestDefaultRulesVintageUnchanged(t *testing.T) {
+	var names []string
+	for _, rule := range DefaultRules().Rules {
+		names = append(names, rule.Name)
+	}
+
+	want := []string{"default-catalog", "bundle-child", "operator-managed", "unsupported-on-capi"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("Vintage default rules changed; Is: %v; Want: %v", names, want)
+	}
+
+	appList := []app.App{
+		newTestApp("wc1-cluster", "cluster", nil),
+		newTestApp("coredns", "giantswarm", map[string]string{"giantswarm.io/managed-by": "wc1-default-apps"}),
+	}
+
+	filteredApps, skippedApps := DefaultRules().Filter(appList)
+	if len(filteredApps) != 2 || len(skippedApps) != 0 {
+		t.Fatalf("Vintage defaults should migrate cluster catalog and default apps; Is: %v; Skipped: %v", filteredApps, skippedApps)
+	}
+}
+
+func TestDefaultCAPIRules(t *testing.T) {
+	var names []string
+	for _, rule := range DefaultCAPIRules().Rules {
+		names = append(names, rule.Name)
+	}
+
+	want := []string{"default-catalog", "bundle-child", "operator-managed", "cluster-catalog", "default-apps-managed", "unsupported-on-capi"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("CAPI default rules not correct; Is: %v; Want: %v", names, want)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(filename, []byte(`rules:
+- name: keep-bundled-loki
+  action: include
+  appNames: ["loki"]
+- name: no-bundles
+  action: exclude
+  labelSelector: giantswarm.io/managed-by
+- name: no-tenant-namespace
+  action: exclude
+  namespaces: ["wc2"]
+- name: no-default-catalogs
+  action: exclude
+  catalogs: ["default*"]
+`), 0600)
+	if err != nil {
+		t.Fatalf("Could not write rules: %s", err)
+	}
+
+	rules, err := LoadRules(filename)
+	if err != nil {
+		t.Fatalf("Could not load rules: %s", err)
+	}
+
+	bundleLabels := map[string]string{"giantswarm.io/managed-by": "observability-bundle"}
+	otherNamespace := newTestApp("grafana", "giantswarm", nil)
+	otherNamespace.Namespace = "wc2"
+
+	appList := []app.App{
+		newTestApp("loki", "giantswarm", bundleLabels),
+		newTestApp("promtail", "giantswarm", bundleLabels),
+		otherNamespace,
+		newTestApp("cert-exporter", "default-test", nil),
+		newTestApp("kyverno", "giantswarm", nil),
+	}
+
+	filteredApps, skippedApps := rules.Filter(appList)
+
+	if len(filteredApps) != 2 || filteredApps[0].Name != "loki" || filteredApps[1].Name != "kyverno" {
+		t.Fatalf("Filtered apps not correct; Is: %v", filteredApps)
+	}
+
+	want := []string{"no-bundles", "no-tenant-namespace", "no-default-catalogs"}
+	for i, skipped := range skippedApps {
+		if skipped.Rule != want[i] {
+			t.Fatalf("Rule skipping %s not correct; Is: %s; Want: %s", skipped.App.Name, skipped.Rule, want[i])
+		}
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	for name, rule := range map[string]Rule{
+		"missing name":           {Action: RuleActionExclude},
+		"invalid action":         {Name: "foo", Action: "skip"},
+		"invalid label selector": {Name: "foo", Action: RuleActionExclude, LabelSelector: "foo in (bar"},
+	} {
+		rules := Rules{Rules: []Rule{rule}}
+
+		err := rules.Validate()
+		if !errors.Is(err, invalidRulesError) {
+			t.Fatalf("Rule with %s should be invalid; Is: %v", name, err)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		value   string
+		match   bool
+	}{
+		{"default", "default", true},
+		{"default", "default-test", false},
+		{"*giantswarm.io/managed-by*", "giantswarm.io/managed-by", true},
+		{"*giantswarm.io/managed-by*", "app.giantswarm.io/managed-by", true},
+		{"*bundle*", "security-bundle", true},
+		{"*bundle*", "customer", false},
+		{"a.b", "axb", false},
+		{"*", "", true},
+		{"*-user-values", "cabbage01-loki-user-values", true},
+		{"*-user-values", "cabbage01-loki-user-values-old", false},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b*c", "aXbYc-d", false},
+		{"a**c", "ac", true},
+	} {
+		if GlobMatch(tc.pattern, tc.value) != tc.match {
+			t.Fatalf("GlobMatch(%q, %q) not correct; Want: %t", tc.pattern, tc.value, tc.match)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package apps
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+)
+
+// ReportState tells whether an app is migrated or not.
+type ReportState string
+
+const (
+	ReportStateMigrated ReportState = "migrated"
+	ReportStateSkipped  ReportState = "skipped"
+)
+
+// Report lists every app of a workload cluster and why it is or is not
+// migrated.
+type Report struct {
+	WcName string        `json:"wcName"`
+	Apps   []ReportEntry `json:"apps"`
+}
+
+// ReportEntry is a single app of a Report. Rule and Reason are set for
+// skipped apps. Migrated apps may have a Reason too, if parts of their config
+// are not migrated.
+type ReportEntry struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	AppName   string      `json:"appName"`
+	Catalog   string      `json:"catalog"`
+	Version   string      `json:"version"`
+	State     ReportState `json:"state"`
+	Rule      string      `json:"rule,omitempty"`
+	Reason    string      `json:"reason,omitempty"`
+
+	// TargetCatalog and TargetVersion are set if a migrated app is changed to
+	// another catalog or version by the upgrade rules.
+	TargetCatalog string `json:"targetCatalog,omitempty"`
+	TargetVersion string `json:"targetVersion,omitempty"`
+}
+
+// ClusterValuesName returns the name of the config map and secret holding the
+// cluster values. They are created by cluster-apps-operator on the
+// destination MC and are therefore never migrated.
+func ClusterValuesName(clusterName string) string {
+	return fmt.Sprintf("%s-cluster-values", clusterName)
+}
+
+// KubeconfigName returns the name of the kubeconfig secret of a workload
+// cluster, which is created with the cluster on the destination MC.
+func KubeconfigName(clusterName string) string {
+	return fmt.Sprintf("%s-kubeconfig", clusterName)
+}
+
+// NewReport creates a report from the result of GetAppCRs.
+func NewReport(clusterName string, migratedApps []app.App, skippedApps []SkippedApp) *Report {
+	report := &Report{
+		WcName: clusterName,
+		Apps:   []ReportEntry{},
+	}
+
+	for _, application := range migratedApps {
+		entry := newReportEntry(application, ReportStateMigrated)
+		if usesClusterValues(clusterName, application) {
+			entry.Reason = fmt.Sprintf("%s config skipped, it is recreated on the destination MC", ClusterValuesName(clusterName))
+		}
+
+		report.Apps = append(report.Apps, entry)
+	}
+
+	for _, skipped := range skippedApps {
+		entry := newReportEntry(skipped.App, ReportStateSkipped)
+		entry.Rule = skipped.Rule
+		entry.Reason = skipped.Reason
+
+		report.Apps = append(report.Apps, entry)
+	}
+
+	return report
+}
+
+// SetUpgrade records the catalog and version a migrated app is changed to.
+func (r *Report) SetUpgrade(application app.App, catalog string, version string) {
+	for i, e := range r.Apps {
+		if e.State != ReportStateMigrated || e.Name != application.GetName() || e.Namespace != application.GetNamespace() {
+			continue
+		}
+
+		if catalog != e.Catalog {
+			r.Apps[i].TargetCatalog = catalog
+		}
+		if version != e.Version {
+			r.Apps[i].TargetVersion = version
+		}
+	}
+}
+
+func newReportEntry(application app.App, state ReportState) ReportEntry {
+	return ReportEntry{
+		Name:      application.GetName(),
+		Namespace: application.GetNamespace(),
+		AppName:   application.Spec.Name,
+		Catalog:   application.Spec.Catalog,
+		Version:   application.Spec.Version,
+		State:     state,
+	}
+}
+
+func usesClusterValues(clusterName string, application app.App) bool {
+	name := ClusterValuesName(clusterName)
+
+	if application.Spec.Config.ConfigMap.Name == name ||
+		application.Spec.Config.Secret.Name == name ||
+		application.Spec.UserConfig.ConfigMap.Name == name ||
+		application.Spec.UserConfig.Secret.Name == name {
+		return true
+	}
+
+	for _, extraConfig := range application.Spec.ExtraConfigs {
+		if extraConfig.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Print writes the report as a table.
+func (r *Report) Print(w io.Writer) {
+	if len(r.Apps) == 0 {
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tNAME\tAPP\tCATALOG\tVERSION\tSTATE\tREASON")
+	for _, e := range r.Apps {
+		reason := e.Reason
+		if e.Rule != "" {
+			reason = fmt.Sprintf("%s (rule: %s)", reason, e.Rule)
+		}
+
+		catalog := e.Catalog
+		if e.TargetCatalog != "" {
+			catalog = fmt.Sprintf("%s -> %s", catalog, e.TargetCatalog)
+		}
+		version := e.Version
+		if e.TargetVersion != "" {
+			version = fmt.Sprintf("%s -> %s", version, e.TargetVersion)
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Namespace, e.Name, e.AppName, catalog, version, e.State, strings.TrimSpace(reason))
+	}
+
+	_ = tw.Flush()
+}
+
+// WriteFile writes the report as JSON.
+func (r *Report) WriteFile(filename string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = os.WriteFile(filename, append(data, '\n'), 0600)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// ReportFileName returns the name of the report written next to a dump file,
+// eg. "wc1-report.json" for "wc1.yaml".
+func ReportFileName(dumpFile string) string {
+	return strings.TrimSuffix(dumpFile, filepath.Ext(dumpFile)) + "-report.json"
+}
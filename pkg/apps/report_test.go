@@ -0,0 +1,85 @@
+package apps
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+)
+
+func TestNewReport(t *testing.T) {
+	customerApp := app.App{}
+	customerApp.Name = "hello-world"
+	customerApp.Namespace = "wc1"
+	customerApp.Spec.Name = "hello-world"
+	customerApp.Spec.Catalog = "giantswarm"
+	customerApp.Spec.Config.ConfigMap.Name = "wc1-cluster-values"
+
+	plainApp := app.App{}
+	plainApp.Name = "loki"
+	plainApp.Namespace = "wc1"
+
+	defaultApp := app.App{}
+	defaultApp.Name = "coredns"
+	defaultApp.Namespace = "wc1"
+	defaultApp.Spec.Catalog = "default"
+
+	migratedApps, skippedApps := DefaultRules().Filter([]app.App{customerApp, plainApp, defaultApp})
+	report := NewReport("wc1", migratedApps, skippedApps)
+
+	if len(report.Apps) != 3 {
+		t.Fatalf("Number of report entries is wrong. Is: %d; Want: %d", len(report.Apps), 3)
+	}
+
+	if report.Apps[0].State != ReportStateMigrated || report.Apps[0].Reason == "" {
+		t.Fatalf("App using the cluster values should be migrated with a reason. Is: %s %q", report.Apps[0].State, report.Apps[0].Reason)
+	}
+
+	if report.Apps[1].State != ReportStateMigrated || report.Apps[1].Reason != "" {
+		t.Fatalf("App without cluster values should be migrated without a reason. Is: %s %q", report.Apps[1].State, report.Apps[1].Reason)
+	}
+
+	if report.Apps[2].State != ReportStateSkipped || report.Apps[2].Rule != "default-catalog" || report.Apps[2].Reason == "" {
+		t.Fatalf("Default app should be skipped with rule and reason. Is: %s %s %q", report.Apps[2].State, report.Apps[2].Rule, report.Apps[2].Reason)
+	}
+}
+
+func TestReportWriteFile(t *testing.T) {
+	skipped := app.App{}
+	skipped.Name = "coredns"
+	skipped.Namespace = "wc1"
+
+	report := NewReport("wc1", nil, []SkippedApp{{App: skipped, Rule: "default-catalog", Reason: "default app"}})
+
+	filename := filepath.Join(t.TempDir(), "report.json")
+	err := report.WriteFile(filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var written Report
+	err = json.Unmarshal(data, &written)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if written.WcName != "wc1" || len(written.Apps) != 1 || written.Apps[0].Rule != "default-catalog" {
+		t.Fatalf("Written report is wrong. Is: %s", data)
+	}
+}
+
+func TestReportFileName(t *testing.T) {
+	is := ReportFileName("/tmp/wc1.yaml")
+	want := "/tmp/wc1-report.json"
+
+	if is != want {
+		t.Fatalf("Report file name is wrong. Is: %s; Want: %s", is, want)
+	}
+}
@@ -0,0 +1,272 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+	"github.com/giantswarm/backoff"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+)
+
+// Stage is the part of the migration which is run for every cluster of a plan.
+type Stage string
+
+const (
+	StagePreflight Stage = "preflight"
+	StagePrepare   Stage = "prepare"
+	StageApply     Stage = "apply"
+)
+
+// Stages lists all stages which can be run in a batch.
+var Stages = []Stage{
+	StagePreflight,
+	StagePrepare,
+	StageApply,
+}
+
+// Config represents the configuration used to run a plan.
+type Config struct {
+	Stage       Stage
+	Parallelism int
+
+	// Finalizer sets the namespace finalizer in prepare and removes it in
+	// apply, like --finalizer does for a single cluster.
+	Finalizer     bool
+	EncryptionKey *age.X25519Identity
+
+	// Rules decide which apps are migrated, the default rules are used if nil.
+	Rules *apps.Rules
+	// MetadataRules rewrite the labels and annotations of the migrated
+	// objects, the default rules are used if nil.
+	MetadataRules *cluster.MetadataRules
+	// UpgradeRules change the version and catalog of the migrated apps.
+	UpgradeRules *cluster.UpgradeRules
+	// ValuesRules transform the values of the migrated config.
+	ValuesRules *cluster.ValuesRules
+
+	// Prerequisites and PrerequisiteTimeout configure the wait for the
+	// prerequisites of each WC in apply.
+	Prerequisites       []cluster.Prerequisite
+	PrerequisiteTimeout time.Duration
+
+	// Out receives the output of all clusters, os.Stdout if nil.
+	Out io.Writer
+}
+
+// Result is the outcome of running a stage for a single cluster.
+type Result struct {
+	WcName   string
+	Stage    Stage
+	Duration time.Duration
+	Message  string
+	Err      error
+}
+
+// Run executes the configured stage for every cluster of the plan, at most
+// config.Parallelism at a time. mcs holds the clients of both MCs, which are
+// shared by all clusters. A failing cluster does not stop the others, the
+// results are returned in the order of the plan. Every line of output of a
+// cluster is prefixed with its name.
+func Run(ctx context.Context, mcs *cluster.Cluster, plan *Plan, config Config) ([]Result, error) {
+	var stage func(ctx context.Context, c *cluster.Cluster, clusterPlan ClusterPlan, config Config) (string, error)
+	switch config.Stage {
+	case StagePreflight:
+		stage = preflight
+	case StagePrepare:
+		stage = prepare
+	case StageApply:
+		stage = apply
+	default:
+		return nil, microerror.Maskf(invalidConfigError, "unsupported stage %q", config.Stage)
+	}
+
+	if config.Finalizer && cluster.Mode(plan.Mode) == cluster.ModeCAPI {
+		return nil, microerror.Maskf(invalidConfigError, "finalizers can not be set on the org namespace of CAPI clusters")
+	}
+
+	if config.Parallelism < 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Parallelism must be at least 1", config)
+	}
+
+	results := make([]Result, len(plan.Clusters))
+	semaphore := make(chan struct{}, config.Parallelism)
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	var outMutex sync.Mutex
+
+	var wg sync.WaitGroup
+	for i, clusterPlan := range plan.Clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			// Clusters which were still waiting for a slot are not started
+			// anymore once the run was cancelled.
+			if ctx.Err() != nil {
+				results[i] = Result{
+					WcName:  clusterPlan.WcName,
+					Stage:   config.Stage,
+					Message: "not started",
+					Err:     microerror.Mask(ctx.Err()),
+				}
+				return
+			}
+
+			c := newCluster(mcs, plan, clusterPlan, config)
+			w := newPrefixWriter(out, &outMutex, clusterPlan.WcName)
+			c.Out = w
+
+			start := time.Now()
+			message, err := stage(ctx, c, clusterPlan, config)
+			_ = w.Flush()
+
+			results[i] = Result{
+				WcName:   clusterPlan.WcName,
+				Stage:    config.Stage,
+				Duration: time.Since(start),
+				Message:  message,
+				Err:      err,
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// newCluster returns a Cluster for a single workload cluster. The MC clients
+// are shared, but everything which is set per workload cluster is copied so
+// clusters can run in parallel.
+func newCluster(mcs *cluster.Cluster, plan *Plan, clusterPlan ClusterPlan, config Config) *cluster.Cluster {
+	srcMC := *mcs.SrcMC
+	srcMC.Namespace = clusterPlan.WcName
+	dstMC := *mcs.DstMC
+
+	return &cluster.Cluster{
+		WcName:              clusterPlan.WcName,
+		OrgNamespace:        clusterPlan.OrgNamespace,
+		DstWcName:           clusterPlan.DestinationWcName,
+		Mode:                cluster.Mode(plan.Mode),
+		SrcMC:               &srcMC,
+		DstMC:               &dstMC,
+		BackOff:             backoff.NewMaxRetries(15, 3*time.Second),
+		EncryptionKey:       config.EncryptionKey,
+		MetadataRules:       config.MetadataRules,
+		UpgradeRules:        config.UpgradeRules,
+		ValuesRules:         config.ValuesRules,
+		Prerequisites:       config.Prerequisites,
+		PrerequisiteTimeout: config.PrerequisiteTimeout,
+	}
+}
+
+func preflight(ctx context.Context, c *cluster.Cluster, clusterPlan ClusterPlan, config Config) (string, error) {
+	health, err := c.SrcMC.GetWCHealth(c.WcName)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	migratedApps, skippedApps, err := c.GetAppCRs(config.Rules)
+	if errors.Is(err, apps.EmptyAppsError) {
+		return fmt.Sprintf("%s, no apps for migration, %d skipped", health, len(skippedApps)), nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	message := fmt.Sprintf("%s, %d apps for migration, %d skipped", health, len(migratedApps), len(skippedApps))
+
+	c.Apps = migratedApps
+	checks, err := c.CheckDestination()
+	c.PrintPrerequisiteChecks(checks)
+	var problems []string
+	for _, check := range checks {
+		if check.Result != cluster.CheckResultPass {
+			problems = append(problems, fmt.Sprintf("%s: %s", check.Result, check.Message))
+		}
+	}
+	if len(problems) > 0 {
+		message = fmt.Sprintf("%s, %s", message, strings.Join(problems, ", "))
+	}
+
+	if err != nil {
+		return message, microerror.Mask(err)
+	}
+
+	return message, nil
+}
+
+func prepare(ctx context.Context, c *cluster.Cluster, clusterPlan ClusterPlan, config Config) (string, error) {
+	dumpFile, err := c.AppYamlFile(clusterPlan.OutputFile)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	f, err := os.OpenFile(dumpFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if config.Finalizer {
+		err = c.SrcMC.SetFinalizerOnNamespace()
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+	}
+
+	c.Apps, _, err = c.GetAppCRs(config.Rules)
+	if errors.Is(err, apps.EmptyAppsError) {
+		return "no apps for migration, wrote empty file", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	err = c.WarnAppVersions()
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	err = c.DumpApps(f)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	message := fmt.Sprintf("%d apps dumped to %s", len(c.Apps), dumpFile)
+	if len(c.Warnings) > 0 {
+		message = fmt.Sprintf("%s, %d warnings", message, len(c.Warnings))
+	}
+
+	return message, nil
+}
+
+func apply(ctx context.Context, c *cluster.Cluster, clusterPlan ClusterPlan, config Config) (string, error) {
+	applied, err := c.ApplyCAPIApps(ctx, clusterPlan.OutputFile)
+	if errors.Is(err, cluster.MigrationFileEmpty) {
+		return "no apps for migration, file was empty", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if config.Finalizer {
+		err = c.SrcMC.RemoveFinalizerOnNamespace()
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+	}
+
+	return fmt.Sprintf("%d objects applied", len(applied)), nil
+}
@@ -0,0 +1,278 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+)
+
+func newTestApp(name string, namespace string) *app.App {
+	return &app.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: app.AppSpec{
+			Name:      name,
+			Namespace: name,
+			Version:   "0.1.0",
+			Catalog:   "giantswarm",
+		},
+	}
+}
+
+func TestLoadPlan(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "plan.yaml")
+	err := os.WriteFile(filename, []byte(`sourceMC: gauss
+destinationMC: golem
+clusters:
+- wcName: wc1
+  orgNamespace: org-foobar
+  outputFile: wc1.yaml
+- wcName: wc2
+  orgNamespace: org-foobar
+`), 0600)
+	if err != nil {
+		t.Fatalf("Could not write plan: %s", err)
+	}
+
+	plan, err := LoadPlan(filename)
+	if err != nil {
+		t.Fatalf("Could not load plan: %s", err)
+	}
+
+	if plan.SourceMC != "gauss" || plan.DestinationMC != "golem" || len(plan.Clusters) != 2 {
+		t.Fatalf("Plan not correct; Is: %+v", plan)
+	}
+
+	if plan.Clusters[0].OutputFile != "wc1.yaml" {
+		t.Fatalf("Plan output file not correct; Is: %s; Want: %s", plan.Clusters[0].OutputFile, "wc1.yaml")
+	}
+}
+
+func TestPlanValidate(t *testing.T) {
+	for name, plan := range map[string]Plan{
+		"missing source": {
+			DestinationMC: "golem",
+			Clusters:      []ClusterPlan{{WcName: "wc1", OrgNamespace: "org-foobar"}},
+		},
+		"missing clusters": {
+			SourceMC:      "gauss",
+			DestinationMC: "golem",
+		},
+		"missing org namespace": {
+			SourceMC:      "gauss",
+			DestinationMC: "golem",
+			Clusters:      []ClusterPlan{{WcName: "wc1"}},
+		},
+		"duplicate wc": {
+			SourceMC:      "gauss",
+			DestinationMC: "golem",
+			Clusters: []ClusterPlan{
+				{WcName: "wc1", OrgNamespace: "org-foobar"},
+				{WcName: "wc1", OrgNamespace: "org-foobar"},
+			},
+		},
+		"duplicate output file": {
+			SourceMC:      "gauss",
+			DestinationMC: "golem",
+			Clusters: []ClusterPlan{
+				{WcName: "wc1", OrgNamespace: "org-foobar", OutputFile: "apps.yaml"},
+				{WcName: "wc2", OrgNamespace: "org-foobar", OutputFile: "apps.yaml"},
+			},
+		},
+	} {
+		err := plan.Validate()
+		if !errors.Is(err, invalidPlanError) {
+			t.Fatalf("Plan with %s should be invalid; Is: %v", name, err)
+		}
+	}
+}
+
+// Test that prepare runs for every cluster and failures are tracked per cluster
+func TestRunPrepare(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = app.AddToScheme(scheme)
+
+	srcClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithIndex(&app.App{}, "metadata.namespace", func(o client.Object) []string {
+			return []string{o.GetNamespace()}
+		}).
+		WithObjects(
+			newTestApp("loki", "wc1"),
+			newTestApp("loki", "wc2"),
+			newTestApp("promtail", "wc2"),
+		).
+		Build()
+
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	rel, _ := filepath.Rel(wd, dir)
+
+	plan := &Plan{
+		SourceMC:      "gauss",
+		DestinationMC: "golem",
+		Clusters: []ClusterPlan{
+			{WcName: "wc1", OrgNamespace: "org-foobar", OutputFile: filepath.Join(rel, "wc1.yaml")},
+			{WcName: "wc2", OrgNamespace: "org-foobar", OutputFile: filepath.Join(rel, "wc2.yaml")},
+			{WcName: "wc3", OrgNamespace: "org-foobar", OutputFile: filepath.Join(rel, "missing", "wc3.yaml")},
+		},
+	}
+
+	mcs := &cluster.Cluster{
+		SrcMC: &cluster.ManagementCluster{Name: "gauss", KubernetesClient: srcClient},
+		DstMC: &cluster.ManagementCluster{Name: "golem", KubernetesClient: fake.NewClientBuilder().WithScheme(scheme).Build()},
+	}
+
+	results, err := Run(context.TODO(), mcs, plan, Config{Stage: StagePrepare, Parallelism: 2})
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Results not correct; Is: %d; Want: %d", len(results), 3)
+	}
+
+	for i, wcName := range []string{"wc1", "wc2"} {
+		if results[i].WcName != wcName || results[i].Err != nil {
+			t.Fatalf("Result of %s not correct; Is: %+v", wcName, results[i])
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, wcName+".yaml"))
+		if err != nil || len(content) == 0 {
+			t.Fatalf("Dump file of %s was not written: %v", wcName, err)
+		}
+	}
+
+	if results[2].WcName != "wc3" || results[2].Err == nil {
+		t.Fatalf("Result of wc3 should be failed; Is: %+v", results[2])
+	}
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	plan := &Plan{}
+
+	_, err := Run(context.TODO(), &cluster.Cluster{}, plan, Config{Stage: "cleanup", Parallelism: 1})
+	if !errors.Is(err, invalidConfigError) {
+		t.Fatalf("Unsupported stage should fail; Is: %v", err)
+	}
+
+	_, err = Run(context.TODO(), &cluster.Cluster{}, plan, Config{Stage: StageApply})
+	if !errors.Is(err, invalidConfigError) {
+		t.Fatalf("Parallelism of zero should fail; Is: %v", err)
+	}
+}
+
+// Test that clusters are not started anymore once the run was cancelled
+func TestRunCancelled(t *testing.T) {
+	plan := &Plan{
+		SourceMC:      "gauss",
+		DestinationMC: "golem",
+		Clusters: []ClusterPlan{
+			{WcName: "wc1", OrgNamespace: "org-foobar", OutputFile: "wc1.yaml"},
+			{WcName: "wc2", OrgNamespace: "org-foobar", OutputFile: "wc2.yaml"},
+		},
+	}
+
+	mcs := &cluster.Cluster{
+		SrcMC: &cluster.ManagementCluster{Name: "gauss"},
+		DstMC: &cluster.ManagementCluster{Name: "golem"},
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	results, err := Run(ctx, mcs, plan, Config{Stage: StageApply, Parallelism: 1})
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+
+	for _, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Fatalf("Result of %s not correct; Is: %v; Want: %s", result.WcName, result.Err, context.Canceled)
+		}
+	}
+}
+
+// Test that the output of clusters running in parallel is prefixed with
+// their names
+func TestRunPreflightOutput(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = app.AddToScheme(scheme)
+	_ = capi.AddToScheme(scheme)
+
+	ready := capi.Condition{Type: capi.ReadyCondition, Status: corev1.ConditionTrue}
+	controlPlaneReady := capi.Condition{Type: capi.ControlPlaneReadyCondition, Status: corev1.ConditionTrue}
+
+	var objects []client.Object
+	for _, wcName := range []string{"wc1", "wc2"} {
+		objects = append(objects,
+			&capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: wcName, Namespace: "org-foobar", Labels: map[string]string{capi.ClusterNameLabel: wcName}},
+				Spec: capi.ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2", Kind: "AWSCluster", Name: wcName},
+				},
+				Status: capi.ClusterStatus{Conditions: capi.Conditions{ready, controlPlaneReady}},
+			},
+			newTestApp("loki", wcName),
+		)
+	}
+
+	srcClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithIndex(&app.App{}, "metadata.namespace", func(o client.Object) []string {
+			return []string{o.GetNamespace()}
+		}).
+		WithObjects(objects...).
+		Build()
+
+	plan := &Plan{
+		SourceMC:      "gauss",
+		DestinationMC: "golem",
+		Clusters: []ClusterPlan{
+			{WcName: "wc1", OrgNamespace: "org-foobar"},
+			{WcName: "wc2", OrgNamespace: "org-foobar"},
+		},
+	}
+
+	mcs := &cluster.Cluster{
+		SrcMC: &cluster.ManagementCluster{Name: "gauss", KubernetesClient: srcClient},
+		DstMC: &cluster.ManagementCluster{Name: "golem", KubernetesClient: fake.NewClientBuilder().WithScheme(scheme).Build()},
+	}
+
+	var out bytes.Buffer
+	_, err := Run(context.TODO(), mcs, plan, Config{Stage: StagePreflight, Parallelism: 2, Out: &out})
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+
+	for _, wcName := range []string{"wc1", "wc2"} {
+		if !strings.Contains(out.String(), "["+wcName+"] CHECK") {
+			t.Fatalf("Checks of %s not printed with prefix; Is: %s", wcName, out.String())
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		if !strings.HasPrefix(line, "[wc1] ") && !strings.HasPrefix(line, "[wc2] ") {
+			t.Fatalf("Line is not prefixed with the WC name: %q", line)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package batch
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidPlanError = &microerror.Error{
+	Kind: "invalidPlanError",
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
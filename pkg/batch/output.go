@@ -0,0 +1,62 @@
+package batch
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// prefixWriter prefixes every line written by a cluster with its name. Only
+// complete lines are passed on, so the output of clusters running in
+// parallel can not be mixed within a line.
+type prefixWriter struct {
+	prefix []byte
+	out    io.Writer
+	// mutex is shared by the writers of all clusters of a run.
+	mutex *sync.Mutex
+
+	buf bytes.Buffer
+}
+
+func newPrefixWriter(out io.Writer, mutex *sync.Mutex, wcName string) *prefixWriter {
+	return &prefixWriter{
+		prefix: []byte("[" + wcName + "] "),
+		out:    out,
+		mutex:  mutex,
+	}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+
+		err := w.writeLine(w.buf.Next(i + 1))
+		if err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes the last line if it was not terminated by a newline.
+func (w *prefixWriter) Flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	line := append(w.buf.Next(w.buf.Len()), '\n')
+
+	return w.writeLine(line)
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, err := w.out.Write(append(append([]byte{}, w.prefix...), line...))
+	return err
+}
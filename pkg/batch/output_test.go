@@ -0,0 +1,27 @@
+package batch
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mutex sync.Mutex
+
+	wc1 := newPrefixWriter(&out, &mutex, "wc1")
+	wc2 := newPrefixWriter(&out, &mutex, "wc2")
+
+	_, _ = fmt.Fprint(wc1, "Applying all non-default ")
+	_, _ = fmt.Fprint(wc2, "Bundle prepared\nApplying")
+	_, _ = fmt.Fprint(wc1, "APP CRs to MC\n")
+	_ = wc1.Flush()
+	_ = wc2.Flush()
+
+	want := "[wc2] Bundle prepared\n[wc1] Applying all non-default APP CRs to MC\n[wc2] Applying\n"
+	if out.String() != want {
+		t.Fatalf("Output is wrong. Is: %q; Want: %q", out.String(), want)
+	}
+}
@@ -0,0 +1,110 @@
+package batch
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+)
+
+// Plan describes the migration of many workload clusters between the same
+// pair of MCs.
+//
+//	sourceMC: gauss
+//	destinationMC: golem
+//	mode: vintage
+//	clusters:
+//	- wcName: wc1
+//	  orgNamespace: org-foobar
+//	  outputFile: gauss-wc1-apps.yaml
+type Plan struct {
+	SourceMC      string        `json:"sourceMC"`
+	DestinationMC string        `json:"destinationMC"`
+	Clusters      []ClusterPlan `json:"clusters"`
+
+	// Mode is the kind of the source clusters, see cluster.Modes. It
+	// defaults to vintage.
+	Mode string `json:"mode,omitempty"`
+}
+
+// ClusterPlan describes the migration of a single workload cluster.
+type ClusterPlan struct {
+	WcName       string `json:"wcName"`
+	OrgNamespace string `json:"orgNamespace"`
+
+	// DestinationWcName renames the WC on the destination MC.
+	DestinationWcName string `json:"destinationWcName,omitempty"`
+
+	// OutputFile is the dump file written by prepare and read by apply. It
+	// defaults to the same name prepare uses for a single cluster.
+	OutputFile string `json:"outputFile,omitempty"`
+}
+
+// LoadPlan reads and validates a plan file.
+func LoadPlan(filename string) (*Plan, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var plan Plan
+	err = k8syaml.UnmarshalStrict(data, &plan)
+	if err != nil {
+		return nil, microerror.Maskf(invalidPlanError, "Could not parse plan %s: %s", filename, err)
+	}
+
+	err = plan.Validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &plan, nil
+}
+
+func (p *Plan) Validate() error {
+	if p.SourceMC == "" {
+		return microerror.Maskf(invalidPlanError, "sourceMC must not be empty")
+	}
+
+	if p.DestinationMC == "" {
+		return microerror.Maskf(invalidPlanError, "destinationMC must not be empty")
+	}
+
+	if p.Mode != "" && !slices.Contains(cluster.Modes, p.Mode) {
+		return microerror.Maskf(invalidPlanError, "mode must be one of %s", strings.Join(cluster.Modes, ", "))
+	}
+
+	if len(p.Clusters) == 0 {
+		return microerror.Maskf(invalidPlanError, "clusters must not be empty")
+	}
+
+	wcNames := map[string]bool{}
+	outputFiles := map[string]bool{}
+	for i, c := range p.Clusters {
+		if c.WcName == "" {
+			return microerror.Maskf(invalidPlanError, "clusters[%d].wcName must not be empty", i)
+		}
+
+		if c.OrgNamespace == "" {
+			return microerror.Maskf(invalidPlanError, "clusters[%d].orgNamespace must not be empty", i)
+		}
+
+		if wcNames[c.WcName] {
+			return microerror.Maskf(invalidPlanError, "clusters[%d].wcName %q is listed more than once", i, c.WcName)
+		}
+		wcNames[c.WcName] = true
+
+		if c.OutputFile != "" {
+			if outputFiles[c.OutputFile] {
+				return microerror.Maskf(invalidPlanError, "clusters[%d].outputFile %q is listed more than once", i, c.OutputFile)
+			}
+			outputFiles[c.OutputFile] = true
+		}
+	}
+
+	return nil
+}
@@ -3,120 +3,157 @@ package cluster
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"time"
 
+	cenkalti "github.com/cenkalti/backoff/v4"
 	"github.com/fatih/color"
 	"github.com/giantswarm/backoff"
 	"github.com/giantswarm/microerror"
-	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (c *Cluster) ApplyCAPIApps(filename string) error {
-	// we skip the app apply if the file is empty
-	fileInfo, err := os.Stat(c.AppYamlFile(filename))
+const (
+	// fieldManager is used to track ownership of fields applied by this tool
+	// via server-side apply.
+	fieldManager = "app-migration-cli"
+
+	// createdLabel marks objects which did not exist on the destination MC
+	// before they were applied by this tool. Only those are removed by rollback.
+	createdLabel = "app-migration-cli.giantswarm.io/created"
+)
+
+// ApplyResult describes what happened to a single object during apply.
+type ApplyResult string
+
+const (
+	ApplyResultCreated    ApplyResult = "created"
+	ApplyResultConfigured ApplyResult = "configured"
+	ApplyResultUnchanged  ApplyResult = "unchanged"
+)
+
+// AppliedObject is the outcome of applying a single object of the dump file.
+type AppliedObject struct {
+	Kind      string      `json:"kind"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Result    ApplyResult `json:"result"`
+}
+
+func (c *Cluster) ApplyCAPIApps(ctx context.Context, filename string) ([]AppliedObject, error) {
+	_, objects, err := c.loadBundle(filename)
 	if err != nil {
-		return microerror.Mask(err)
+		return nil, microerror.Mask(err)
+	}
+
+	objects, err = applyOrder(objects)
+	if err != nil {
+		return nil, microerror.Mask(err)
 	}
-	// Check if the file size is 0
-	if fileInfo.Size() == 0 {
-		return microerror.Maskf(MigrationFileEmpty, "Migration File is empty. Nothing to migrate")
+
+	err = c.WaitForPrerequisites(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
 	}
 
-	// waitloop til kubeconfig/default-cluster-values are found
-	for {
-		cmClusterValuesExists, err := checkIfObjectExists(c.DstMC.KubernetesClient, c.OrgNamespace, fmt.Sprintf("%s-cluster-values", c.WcName), configmapType)
-		if err != nil {
-			fmt.Printf("Error checking existence of %s/%s-cluster-values: %s\n", configmapType, c.WcName, err)
-			time.Sleep(time.Second * 5)
-			continue
+	_, _ = fmt.Fprintf(c.stdout(), "Applying all non-default APP CRs to MC\n")
+
+	var applied []AppliedObject
+	for _, obj := range objects {
+		if ctx.Err() != nil {
+			return applied, microerror.Mask(ctx.Err())
 		}
 
-		if cmClusterValuesExists {
-			secretClusterValuesExists, err := checkIfObjectExists(c.DstMC.KubernetesClient, c.OrgNamespace, fmt.Sprintf("%s-cluster-values", c.WcName), secretType)
+		var result ApplyResult
+
+		applyManifest := func() error {
+			var err error
+			result, err = applyObject(ctx, c.DstMC.KubernetesClient, obj)
 			if err != nil {
-				fmt.Printf("Error checking existence of %s/%s-cluster-values: %s\n", secretType, c.WcName, err)
-				time.Sleep(time.Second * 5)
-				continue
+				return microerror.Mask(err)
 			}
+			return nil
+		}
 
-			if secretClusterValuesExists {
-				kubeconfigExists, err := checkIfObjectExists(c.DstMC.KubernetesClient, c.OrgNamespace, fmt.Sprintf("%s-kubeconfig", c.WcName), secretType)
-				if err != nil {
-					fmt.Printf("Error checking existence of %s/%s-kubeconfig: %s\n", secretType, c.WcName, err)
-					time.Sleep(time.Second * 5)
-					continue
-				}
-
-				if kubeconfigExists {
-					color.Yellow("\nAll prerequistes are found on the new MC for app migration")
-					break
-				}
-			}
+		err = retry(ctx, applyManifest, c.BackOff)
+		if err != nil {
+			return applied, microerror.Mask(err)
 		}
-	}
 
-	fmt.Printf("Applying all non-default APP CRs to MC\n")
-	applyManifests := func() error {
-		//nolint:gosec
-		e := exec.Command("kubectl", "--context", fmt.Sprintf("gs-%s", c.DstMC.Name), "apply", "-f", c.AppYamlFile(filename))
+		_, _ = fmt.Fprintf(c.stdout(), "%s/%s/%s %s\n", obj.GetKind(), obj.GetNamespace(), obj.GetName(), result)
+		applied = append(applied, AppliedObject{
+			Kind:      obj.GetKind(),
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Result:    result,
+		})
+	}
 
-		e.Stderr = os.Stderr
-		e.Stdin = os.Stdin
+	_, _ = color.New(color.FgGreen).Fprintf(c.stdout(), "All non-default apps applied successfully.\n\n")
+	return applied, nil
+}
 
-		err := e.Run()
-		if err != nil {
-			return microerror.Mask(err)
-		}
-		return nil
+// applyObject applies a single object with server-side apply and reports
+// whether it was created, changed or left untouched.
+func applyObject(ctx context.Context, k8sClient client.Client, obj *unstructured.Unstructured) (ApplyResult, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+
+	result := ApplyResultConfigured
+	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if errors.IsNotFound(err) {
+		result = ApplyResultCreated
+		existing = nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
 	}
 
-	err = backoff.Retry(applyManifests, c.BackOff)
+	patch := newApplyPatch(obj, existing)
+
+	err = k8sClient.Patch(ctx, patch, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
 	if err != nil {
-		return microerror.Mask(err)
+		return "", microerror.Mask(err)
 	}
-	color.Green("All non-default apps applied successfully.\n\n")
-	return nil
-}
 
-func checkIfObjectExists(k8s client.Client, nameSpace string, name string, resourceKind string) (bool, error) {
-	switch resourceKind {
-	case secretType:
-		var secret v1.Secret
-		err := k8s.Get(context.TODO(), client.ObjectKey{
-			Name:      name,
-			Namespace: nameSpace,
-		},
-			&secret)
+	if result == ApplyResultConfigured && patch.GetResourceVersion() == existing.GetResourceVersion() {
+		result = ApplyResultUnchanged
+	}
 
-		if err != nil {
-			if errors.IsNotFound(err) {
-				return false, nil
-			}
-			return false, err
+	return result, nil
+}
+
+// newApplyPatch returns the object to send with server-side apply. It works on
+// a copy, so a retry sends the unmodified manifest again. The created label is
+// only set on new objects and kept on objects which already carry it, as
+// leaving it out of the patch would remove it again.
+func newApplyPatch(obj *unstructured.Unstructured, existing *unstructured.Unstructured) *unstructured.Unstructured {
+	patch := obj.DeepCopy()
+	patch.SetResourceVersion("")
+	patch.SetManagedFields(nil)
+
+	if existing == nil || isCreatedByTool(existing) {
+		labels := patch.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
 		}
-		return true, nil
+		labels[createdLabel] = "true"
+		patch.SetLabels(labels)
+	}
 
-	case configmapType:
-		var cm v1.ConfigMap
-		err := k8s.Get(context.TODO(), client.ObjectKey{
-			Name:      name,
-			Namespace: nameSpace,
-		},
-			&cm)
+	return patch
+}
 
-		if err != nil {
-			if errors.IsNotFound(err) {
-				return false, nil
-			}
-			return false, err
-		}
-		return true, nil
+func isCreatedByTool(obj *unstructured.Unstructured) bool {
+	return obj.GetLabels()[createdLabel] == "true"
+}
 
-	default:
-		return false, fmt.Errorf("unsupported resource kind: %s", resourceKind)
+// retry runs o until it succeeds or b gives up like backoff.Retry. It stops
+// waiting for the next attempt as soon as ctx is cancelled.
+func retry(ctx context.Context, o backoff.Operation, b backoff.BackOff) error {
+	err := backoff.Retry(o, cenkalti.WithContext(b, ctx))
+	if err != nil {
+		return microerror.Mask(err)
 	}
+
+	return nil
 }
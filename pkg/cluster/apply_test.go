@@ -0,0 +1,277 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/backoff"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newApplyFakeClient returns a fake client which emulates server-side apply
+// including dry-run, as the controller-runtime fake client does not support
+// apply patches.
+func newApplyFakeClient(initObjs ...runtime.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(initObjs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if patch.Type() != types.ApplyPatchType {
+					return c.Patch(ctx, obj, patch, opts...)
+				}
+
+				u := obj.(*unstructured.Unstructured)
+
+				patchOptions := &client.PatchOptions{}
+				patchOptions.ApplyOptions(opts)
+				dryRun := len(patchOptions.DryRun) > 0
+
+				existing := &unstructured.Unstructured{}
+				existing.SetGroupVersionKind(u.GroupVersionKind())
+				err := c.Get(ctx, client.ObjectKeyFromObject(u), existing)
+				if apierrors.IsNotFound(err) {
+					if dryRun {
+						return nil
+					}
+					return c.Create(ctx, u)
+				} else if err != nil {
+					return err
+				}
+
+				merged := existing.DeepCopy()
+				for key, value := range u.Object {
+					if key != "metadata" {
+						merged.Object[key] = value
+					}
+				}
+				if equality.Semantic.DeepEqual(existing.Object, merged.Object) {
+					existing.DeepCopyInto(u)
+					return nil
+				}
+
+				if dryRun {
+					merged.DeepCopyInto(u)
+					return nil
+				}
+
+				err = c.Update(ctx, merged)
+				if err != nil {
+					return err
+				}
+				merged.DeepCopyInto(u)
+				return nil
+			},
+		}).
+		Build()
+}
+
+const (
+	testWcName       = "cabbage01"
+	testOrgNamespace = "org-capa-migration-testing"
+)
+
+// newTestCluster returns a cluster migrating the WC cabbage01 from gauss to
+// golem. The destination MC holds dstObjects and emulates server-side apply.
+func newTestCluster(dstObjects ...runtime.Object) *Cluster {
+	return &Cluster{
+		WcName:       testWcName,
+		OrgNamespace: testOrgNamespace,
+		SrcMC: &ManagementCluster{
+			Name:             "gauss",
+			Namespace:        testWcName,
+			KubernetesClient: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		},
+		DstMC: &ManagementCluster{
+			Name:             "golem",
+			KubernetesClient: newApplyFakeClient(dstObjects...),
+		},
+		BackOff: backoff.NewMaxRetries(0, 0),
+	}
+}
+
+// newTestApp returns the app cabbage01-<name> in the WC namespace of the
+// source MC.
+func newTestApp(name string, catalog string, version string) app.App {
+	return app.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testWcName + "-" + name,
+			Namespace: testWcName,
+		},
+		Spec: app.AppSpec{
+			Name:      name,
+			Namespace: name,
+			Version:   version,
+			Catalog:   catalog,
+		},
+	}
+}
+
+func writeDumpFile(t *testing.T, content string) string {
+	filename := filepath.Join(t.TempDir(), "apps.yaml")
+
+	err := os.WriteFile(filename, []byte(content), 0600)
+	if err != nil {
+		t.Fatalf("Could not write dump file: %s", err)
+	}
+
+	rel, err := filepath.Rel(mustGetwd(t), filename)
+	if err != nil {
+		t.Fatalf("Could not build relative path: %s", err)
+	}
+
+	return rel
+}
+
+func mustGetwd(t *testing.T) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Could not get working directory: %s", err)
+	}
+	return wd
+}
+
+func prerequisiteObjects(wcName string, orgNamespace string) []runtime.Object {
+	return []runtime.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-cluster-values", wcName), Namespace: orgNamespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-cluster-values", wcName), Namespace: orgNamespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-kubeconfig", wcName), Namespace: orgNamespace}},
+	}
+}
+
+func TestApplyCAPIAppsResults(t *testing.T) {
+	const wcName = "cabbage01"
+	const orgNamespace = "org-capa-migration-testing"
+
+	dump := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cabbage01-new
+  namespace: org-capa-migration-testing
+data:
+  values: "foo: bar"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cabbage01-same
+  namespace: org-capa-migration-testing
+data:
+  values: "foo: bar"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cabbage01-changed
+  namespace: org-capa-migration-testing
+data:
+  values: "foo: baz"
+---
+apiVersion: application.giantswarm.io/v1alpha1
+kind: App
+metadata:
+  name: cabbage01-loki
+  namespace: org-capa-migration-testing
+spec:
+  catalog: giantswarm
+  name: loki
+  namespace: loki
+  version: 0.1.0
+---
+`
+
+	initObjs := append(prerequisiteObjects(wcName, orgNamespace),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-same", Namespace: orgNamespace},
+			Data:       map[string]string{"values": "foo: bar"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-changed", Namespace: orgNamespace},
+			Data:       map[string]string{"values": "foo: bar"},
+		},
+	)
+
+	k8sClient := newApplyFakeClient(initObjs...)
+
+	c := Cluster{
+		WcName:       wcName,
+		OrgNamespace: orgNamespace,
+		SrcMC:        &ManagementCluster{Name: "foo"},
+		DstMC:        &ManagementCluster{Name: "bar", KubernetesClient: k8sClient},
+		BackOff:      backoff.NewMaxRetries(0, 0),
+	}
+
+	applied, err := c.ApplyCAPIApps(context.TODO(), writeDumpFile(t, dump))
+	if err != nil {
+		t.Fatalf("Apply failed: %s", err)
+	}
+
+	want := map[string]ApplyResult{
+		"ConfigMap/cabbage01-new":     ApplyResultCreated,
+		"ConfigMap/cabbage01-same":    ApplyResultUnchanged,
+		"ConfigMap/cabbage01-changed": ApplyResultConfigured,
+		"App/cabbage01-loki":          ApplyResultCreated,
+	}
+
+	if len(applied) != len(want) {
+		t.Fatalf("Applied objects not correct; Is: %d; Want: %d", len(applied), len(want))
+	}
+
+	for _, obj := range applied {
+		key := fmt.Sprintf("%s/%s", obj.Kind, obj.Name)
+		if want[key] != obj.Result {
+			t.Fatalf("Apply result of %s not correct; Is: %s; Want: %s", key, obj.Result, want[key])
+		}
+	}
+
+	var cm corev1.ConfigMap
+	err = k8sClient.Get(context.TODO(), client.ObjectKey{Name: "cabbage01-changed", Namespace: orgNamespace}, &cm)
+	if err != nil {
+		t.Fatalf("Could not get configmap: %s", err)
+	}
+	if cm.Data["values"] != "foo: baz" {
+		t.Fatalf("ConfigMap data not updated; Is: %s; Want: %s", cm.Data["values"], "foo: baz")
+	}
+}
+
+func TestApplyCAPIAppsEmptyFile(t *testing.T) {
+	c := Cluster{
+		WcName: "cabbage01",
+		SrcMC:  &ManagementCluster{Name: "foo"},
+	}
+
+	_, err := c.ApplyCAPIApps(context.TODO(), writeDumpFile(t, ""))
+	if !errors.Is(err, MigrationFileEmpty) {
+		t.Fatalf("Empty dump file should return MigrationFileEmpty; Is: %v", err)
+	}
+}
+
+func TestDecodeManifests(t *testing.T) {
+	objects, err := decodeManifests(strings.NewReader("---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n---\n---\n"))
+	if err != nil {
+		t.Fatalf("Could not decode manifests: %s", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("Empty documents should be skipped; Is: %d; Want: %d", len(objects), 1)
+	}
+
+	_, err = decodeManifests(strings.NewReader("metadata:\n  name: foo\n"))
+	if err == nil {
+		t.Fatalf("Document without kind should fail to decode")
+	}
+}
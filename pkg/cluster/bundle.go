@@ -0,0 +1,230 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/app-migration-cli/pkg/project"
+)
+
+const (
+	// BundleAPIVersion is the version of the bundle format written by prepare.
+	// It must be bumped whenever the format changes incompatibly.
+	BundleAPIVersion = "app-migration-cli.giantswarm.io/v1alpha1"
+	BundleKind       = "MigrationBundle"
+)
+
+// BundleManifest is the header document of a dump file. It records where the
+// objects were prepared from and for, so apply can reject mismatching files.
+type BundleManifest struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	SourceMC      string    `json:"sourceMC"`
+	DestinationMC string    `json:"destinationMC"`
+	WcName        string    `json:"wcName"`
+	OrgNamespace  string    `json:"orgNamespace"`
+	ToolVersion   string    `json:"toolVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+
+	// Mode is the mode the bundle was prepared in, vintage if empty.
+	Mode string `json:"mode,omitempty"`
+	// DestinationWcName is set if the WC has a different name on the
+	// destination MC.
+	DestinationWcName string `json:"destinationWcName,omitempty"`
+
+	// SecretEncryption is set when secret data in the bundle is encrypted.
+	SecretEncryption string `json:"secretEncryption,omitempty"`
+
+	Objects []BundleObject `json:"objects,omitempty"`
+}
+
+// BundleObject references a single object contained in the dump file.
+type BundleObject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+func (c *Cluster) newBundleManifest(objects [][]byte) (*BundleManifest, error) {
+	manifest := &BundleManifest{
+		APIVersion:   BundleAPIVersion,
+		Kind:         BundleKind,
+		SourceMC:     c.SrcMC.Name,
+		WcName:       c.WcName,
+		OrgNamespace: c.OrgNamespace,
+		ToolVersion:  project.Version(),
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	if c.DstMC != nil {
+		manifest.DestinationMC = c.DstMC.Name
+	}
+
+	if c.Mode != "" && c.Mode != ModeVintage {
+		manifest.Mode = string(c.Mode)
+	}
+
+	if c.DestinationWcName() != c.WcName {
+		manifest.DestinationWcName = c.DestinationWcName()
+	}
+
+	if c.EncryptionKey != nil {
+		manifest.SecretEncryption = secretEncryptionAge
+	}
+
+	for _, obj := range objects {
+		var u unstructured.Unstructured
+
+		err := k8syaml.Unmarshal(obj, &u.Object)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		manifest.Objects = append(manifest.Objects, BundleObject{
+			Kind:      u.GetKind(),
+			Name:      u.GetName(),
+			Namespace: u.GetNamespace(),
+		})
+	}
+
+	return manifest, nil
+}
+
+// Validate checks that the bundle was prepared for the given migration.
+func (m *BundleManifest) Validate(c *Cluster) error {
+	if m.APIVersion != BundleAPIVersion {
+		return microerror.Maskf(bundleMismatch, "Unsupported bundle version %q, want %q", m.APIVersion, BundleAPIVersion)
+	}
+
+	if m.WcName != c.WcName {
+		return microerror.Maskf(bundleMismatch, "Bundle was prepared for WC %q, not %q", m.WcName, c.WcName)
+	}
+
+	if c.DstWcName != "" && m.destinationWcName() != c.DstWcName {
+		return microerror.Maskf(bundleMismatch, "Bundle was prepared for destination WC %q, not %q", m.destinationWcName(), c.DstWcName)
+	}
+
+	if m.OrgNamespace != c.OrgNamespace {
+		return microerror.Maskf(bundleMismatch, "Bundle was prepared for org namespace %q, not %q", m.OrgNamespace, c.OrgNamespace)
+	}
+
+	if c.DstMC != nil && m.DestinationMC != c.DstMC.Name {
+		return microerror.Maskf(bundleMismatch, "Bundle was prepared for destination MC %q, not %q", m.DestinationMC, c.DstMC.Name)
+	}
+
+	return nil
+}
+
+func (m *BundleManifest) destinationWcName() string {
+	if m.DestinationWcName != "" {
+		return m.DestinationWcName
+	}
+
+	return m.WcName
+}
+
+// readBundle decodes a dump file and splits off the bundle header. Dump files
+// written by older versions have no header, in that case the manifest is nil.
+func readBundle(r io.Reader) (*BundleManifest, []*unstructured.Unstructured, error) {
+	objects, err := decodeManifests(r)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+
+	if len(objects) == 0 || objects[0].GetKind() != BundleKind {
+		return nil, objects, nil
+	}
+
+	var manifest BundleManifest
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(objects[0].Object, &manifest)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+
+	return &manifest, objects[1:], nil
+}
+
+// loadBundle reads the dump file, validates its header against the cluster
+// and decrypts encrypted secrets, so the returned objects can be sent to the
+// destination MC as they are.
+func (c *Cluster) loadBundle(filename string) (*BundleManifest, []*unstructured.Unstructured, error) {
+	manifest, objects, err := c.readBundleFile(filename)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+
+	// the prerequisites on the destination MC are named after the WC there
+	if manifest != nil && c.DstWcName == "" {
+		c.DstWcName = manifest.DestinationWcName
+	}
+
+	if manifest != nil && manifest.SecretEncryption != "" && c.EncryptionKey == nil {
+		return nil, nil, microerror.Maskf(invalidEncryptionKey, "Bundle contains %s encrypted secrets but no encryption key was given", manifest.SecretEncryption)
+	}
+
+	for _, obj := range objects {
+		if isEncryptedSecret(obj) {
+			err = decryptSecret(obj, c.EncryptionKey)
+			if err != nil {
+				return nil, nil, microerror.Mask(err)
+			}
+		}
+	}
+
+	return manifest, objects, nil
+}
+
+// readBundleFile reads the dump file and validates its header against the
+// cluster. Encrypted secrets are returned as they are.
+func (c *Cluster) readBundleFile(filename string) (*BundleManifest, []*unstructured.Unstructured, error) {
+	path, err := c.AppYamlFile(filename)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+
+	// we skip the file if it is empty
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+	// Check if the file size is 0
+	if fileInfo.Size() == 0 {
+		return nil, nil, microerror.Maskf(MigrationFileEmpty, "Migration File is empty. Nothing to migrate")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	manifest, objects, err := readBundle(f)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+
+	if manifest != nil {
+		err = manifest.Validate(c)
+		if err != nil {
+			return nil, nil, microerror.Mask(err)
+		}
+		_, _ = fmt.Fprintf(c.stdout(), "Bundle prepared on %s from %s/%s by %s\n", manifest.CreatedAt.Format(time.RFC3339), manifest.SourceMC, manifest.WcName, manifest.ToolVersion)
+	} else {
+		_, _ = color.New(color.FgYellow).Fprintf(c.stdout(), "Dump file has no bundle header, skipping validation against the given flags\n")
+	}
+
+	if len(objects) == 0 {
+		return nil, nil, microerror.Maskf(MigrationFileEmpty, "Migration File contains no objects. Nothing to migrate")
+	}
+
+	return manifest, objects, nil
+}
@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+
+	"github.com/giantswarm/app-migration-cli/pkg/project"
+)
+
+// Test that a dumped bundle can be read back including its header
+func TestDumpAppsBundleRoundtrip(t *testing.T) {
+	c := newTestCluster()
+	c.Apps = []app.App{newTestApp("loki", "giantswarm", "0.1.0")}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "apps.yaml"))
+	if err != nil {
+		t.Fatalf("Could not create dump file: %s", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	err = c.DumpApps(f)
+	if err != nil {
+		t.Fatalf("Could not dump apps: %s", err)
+	}
+
+	_, err = f.Seek(0, 0)
+	if err != nil {
+		t.Fatalf("Could not rewind dump file: %s", err)
+	}
+
+	manifest, objects, err := readBundle(f)
+	if err != nil {
+		t.Fatalf("Could not read bundle: %s", err)
+	}
+
+	if manifest == nil {
+		t.Fatalf("Bundle header is missing")
+	}
+
+	if manifest.SourceMC != "gauss" || manifest.DestinationMC != "golem" || manifest.WcName != "cabbage01" || manifest.OrgNamespace != "org-capa-migration-testing" {
+		t.Fatalf("Bundle header not correct; Is: %+v", manifest)
+	}
+
+	if manifest.ToolVersion != project.Version() {
+		t.Fatalf("Bundle tool version not correct; Is: %s; Want: %s", manifest.ToolVersion, project.Version())
+	}
+
+	if manifest.CreatedAt.IsZero() {
+		t.Fatalf("Bundle creation timestamp is missing")
+	}
+
+	if len(objects) != 1 || len(manifest.Objects) != 1 {
+		t.Fatalf("Bundle objects not correct; Is: %d/%d; Want: 1/1", len(objects), len(manifest.Objects))
+	}
+
+	if manifest.Objects[0].Kind != "App" || manifest.Objects[0].Name != objects[0].GetName() {
+		t.Fatalf("Bundle object index not correct; Is: %+v", manifest.Objects[0])
+	}
+
+	err = manifest.Validate(c)
+	if err != nil {
+		t.Fatalf("Bundle should be valid for the cluster it was prepared for: %s", err)
+	}
+}
+
+func TestBundleManifestValidateMismatch(t *testing.T) {
+	c := newTestCluster()
+	c.Apps = []app.App{newTestApp("loki", "giantswarm", "0.1.0")}
+
+	manifest, err := c.newBundleManifest(nil)
+	if err != nil {
+		t.Fatalf("Could not create bundle manifest: %s", err)
+	}
+
+	for name, modify := range map[string]func(c *Cluster){
+		"wc name":        func(c *Cluster) { c.WcName = "cabbage02" },
+		"org namespace":  func(c *Cluster) { c.OrgNamespace = "org-foobar" },
+		"destination mc": func(c *Cluster) { c.DstMC.Name = "grizzly" },
+		"destination wc": func(c *Cluster) { c.DstWcName = "cabbage03" },
+	} {
+		other := newTestCluster()
+		modify(other)
+
+		err = manifest.Validate(other)
+		if !errors.Is(err, bundleMismatch) {
+			t.Fatalf("Mismatching %s should fail validation; Is: %v", name, err)
+		}
+	}
+}
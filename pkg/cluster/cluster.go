@@ -2,21 +2,25 @@ package cluster
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"slices"
-	"strings"
+	"time"
 
+	"filippo.io/age"
 	apps "github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	gsv1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
+	providerv1alpha1 "github.com/giantswarm/apiextensions/v6/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/backoff"
 	"github.com/giantswarm/microerror"
 	"golang.org/x/net/context"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,6 +44,7 @@ func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = capi.AddToScheme(scheme)
 	_ = gsv1alpha3.AddToScheme(scheme)
+	_ = providerv1alpha1.AddToScheme(scheme)
 	//	_ = kubeadmv1beta1.AddToScheme(scheme)
 	_ = apps.AddToScheme(scheme)
 }
@@ -49,10 +54,50 @@ type Cluster struct {
 	OrgNamespace string
 	Apps         []apps.App
 
+	// Mode selects how the apps are found on the source MC, ModeVintage if
+	// empty.
+	Mode Mode
+	// DstWcName is the name of the WC on the destination MC, WcName if empty.
+	DstWcName string
+
+	// MetadataRules rewrite the labels and annotations of the migrated
+	// objects, the default rules are used if nil.
+	MetadataRules *MetadataRules
+	// UpgradeRules change the version and catalog of the migrated apps, they
+	// are kept if nil.
+	UpgradeRules *UpgradeRules
+	// ValuesRules transform the values of the migrated config maps and
+	// secrets, they are kept if nil.
+	ValuesRules *ValuesRules
+
+	// Prerequisites must exist on the destination MC before the apps are
+	// applied, the default ones are used if nil. PrerequisiteTimeout limits
+	// the time to wait for them, there is no limit if it is 0.
+	Prerequisites       []Prerequisite
+	PrerequisiteTimeout time.Duration
+
+	// Warnings collects problems which did not stop the migration.
+	Warnings []string
+	// Out receives the progress output, os.Stdout if nil.
+	Out io.Writer
+
 	SrcMC *ManagementCluster
 	DstMC *ManagementCluster
 
 	BackOff backoff.BackOff
+
+	// EncryptionKey is used to encrypt secrets written to the dump file and
+	// to decrypt them again on apply. Secrets are stored in plain text if nil.
+	EncryptionKey *age.X25519Identity
+}
+
+// stdout returns the writer for the progress output.
+func (c *Cluster) stdout() io.Writer {
+	if c.Out != nil {
+		return c.Out
+	}
+
+	return os.Stdout
 }
 
 type ManagementCluster struct {
@@ -69,147 +114,154 @@ type AppExtraConfig struct {
 	Yaml      []byte
 }
 
-func (c *Cluster) AppYamlFile(filename string) string {
+// AppYamlFile returns the path of the dump file. Without filename it
+// defaults to <src>-<wc>-apps.yaml, which needs the source MC.
+func (c *Cluster) AppYamlFile(filename string) (string, error) {
 	wd, _ := os.Getwd()
 
 	if filename == "" {
-		filename = fmt.Sprintf("%s-%s-apps.yaml", c.SrcMC.Name, c.WcName)
-	}
-
-	return fmt.Sprintf("%s/%s", wd, filename)
-}
-
-// todo: access clusterName by *Cluster
-func (c *ManagementCluster) GetWCHealth(clusterName string) (string, error) {
-
-	ctx := context.TODO()
-
-	capiCluster, err := c.getCluster(ctx, clusterName)
-	if err != nil {
-		return "", microerror.Mask(err)
-	}
-
-	var awsCluster *gsv1alpha3.AWSCluster
-
-	if capiCluster.Spec.InfrastructureRef.Name != "" {
-		awsCluster, err = c.getAwsClusterByName(ctx, capiCluster.Spec.InfrastructureRef.Name)
-		if err != nil {
-			return "", microerror.Mask(err)
+		if c.SrcMC == nil {
+			return "", microerror.Maskf(dumpFileNotSet, "dump file must be given, the default name needs the source MC")
 		}
-	} else {
-		return "", microerror.Maskf(clusterNameNotFound, "AWSCluster Name not found for %s", clusterName)
-	}
-
-	health := getLastAwsCondition(awsCluster.Status.Cluster.Conditions)
-	if slices.Contains(validClusterStates, health) {
-		return health, nil
-	} else {
-		return "", microerror.Maskf(clusterUnhealthy, "WorkloadCluster not in a healthy condition")
-	}
-
-}
-
-func getLastAwsCondition(cond []gsv1alpha3.CommonClusterStatusCondition) string {
-	if len(cond) < 1 {
-		return "n/a"
+		filename = fmt.Sprintf("%s-%s-apps.yaml", c.SrcMC.Name, c.WcName)
 	}
 
-	return cond[0].Condition
+	return fmt.Sprintf("%s/%s", wd, filename), nil
 }
 
-func (c *ManagementCluster) getAwsClusterByName(ctx context.Context, clusterName string) (*gsv1alpha3.AWSCluster, error) {
-	objList := &gsv1alpha3.AWSClusterList{}
+func (c *ManagementCluster) getCluster(ctx context.Context, clusterName string) (*capi.Cluster, error) {
+	objList := &capi.ClusterList{}
 	selector := client.MatchingLabels{capi.ClusterNameLabel: clusterName}
-
 	err := c.KubernetesClient.List(ctx, objList, selector)
-	if err != nil {
+	if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+		// vintage KVM MCs have no CAPI CRDs
+		return nil, microerror.Maskf(clusterCRDNotInstalled, "Cluster CRD not installed on %s", c.Name)
+	} else if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	if len(objList.Items) == 0 {
-		return nil, microerror.Maskf(clusterNotFound, "Cluster not found for %s", clusterName)
+		return nil, microerror.Maskf(clusterNotFound, "Cluster not found for %s-%s", c.Name, clusterName)
 	}
 
 	if len(objList.Items) > 1 {
-		return nil, microerror.Maskf(clusterNotFound, "More than one Cluster found with name %s", clusterName)
+		return nil, microerror.Maskf(clusterNotUnique, "More than one Cluster found with name %s-%s", c.Name, clusterName)
 	}
 
 	return &objList.Items[0], nil
 }
 
-func (c *ManagementCluster) getCluster(ctx context.Context, clusterName string) (*capi.Cluster, error) {
-	objList := &capi.ClusterList{}
-	selector := client.MatchingLabels{capi.ClusterNameLabel: clusterName}
-	err := c.KubernetesClient.List(ctx, objList, selector)
-	if err != nil {
-		return nil, microerror.Mask(err)
-	}
+// ConnectionConfig describes how to connect to a single MC.
+type ConnectionConfig struct {
+	// Name of the MC. It names the dump file and the default context.
+	Name string
+
+	// Kubeconfig is the kubeconfig file to use, $KUBECONFIG or ~/.kube/config
+	// if empty.
+	Kubeconfig string
+	// Context is the kubeconfig context to use, gs-<Name> if empty.
+	Context string
+	// InCluster uses the service account of the pod the tool runs in instead
+	// of a kubeconfig.
+	InCluster bool
+
+	// LoginProvider creates the context if it is missing from the kubeconfig.
+	// The context must exist already if nil.
+	LoginProvider LoginProvider
+
+	// Out receives the connection messages and the output of the login,
+	// os.Stdout if nil. It becomes the Out of the Cluster returned by Login.
+	Out io.Writer
+}
 
-	if len(objList.Items) == 0 {
-		return nil, microerror.Maskf(clusterNotFound, "Cluster not found for %s-%s", c.Name, clusterName)
+func (c ConnectionConfig) stdout() io.Writer {
+	if c.Out != nil {
+		return c.Out
 	}
 
-	if len(objList.Items) > 1 {
-		return nil, microerror.Maskf(clusterNotFound, "More than one Cluster found with name %s-%s", c.Name, clusterName)
+	return os.Stdout
+}
+
+func (c ConnectionConfig) contextName() string {
+	if c.Context != "" {
+		return c.Context
 	}
 
-	return &objList.Items[0], nil
+	return contextNameFromCluster([]string{c.Name})
 }
 
-// todo: migrate to single cluster login
-func Login(srcMC string, dstMc string) (*Cluster, error) {
-	srcMcClient, _, err := loginOrReuseKubeconfig([]string{srcMC})
+func Login(src ConnectionConfig, dst ConnectionConfig) (*Cluster, error) {
+	srcMC, err := LoginMC(src)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
-	dstMcClient, _, err := loginOrReuseKubeconfig([]string{dstMc})
+	dstMC, err := LoginMC(dst)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	return &Cluster{
-		SrcMC: &ManagementCluster{
-			Name:             srcMC,
-			KubernetesClient: srcMcClient,
-		},
-		DstMC: &ManagementCluster{
-			Name:             dstMc,
-			KubernetesClient: dstMcClient,
-		},
+		SrcMC: srcMC,
+		DstMC: dstMC,
+		Out:   src.Out,
 	}, nil
 }
 
-// LoginOrReuseKubeconfig will return k8s client for the specific wc or MC client, it will try if there is already existing context or login if its missing
-func loginOrReuseKubeconfig(cluster []string) (client.Client, kubernetes.Interface, error) {
-	ctrlClient, clientSet, err := getK8sClientFromKubeconfig(contextNameFromCluster(cluster))
-	if err != nil && strings.Contains(err.Error(), "does not exist") {
-		// login
-		fmt.Printf("Context for cluster %s not found, executing 'opsctl login', check your browser window.\n", cluster)
-		err = loginIntoCLuster(cluster)
+// LoginMC returns a single MC, for commands which do not need both.
+func LoginMC(config ConnectionConfig) (*ManagementCluster, error) {
+	mcClient, _, err := loginOrReuseKubeconfig(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &ManagementCluster{
+		Name:             config.Name,
+		KubernetesClient: mcClient,
+	}, nil
+}
+
+// LoginOrReuseKubeconfig will return k8s client for the MC, it will use the existing context or login with the login provider if its missing
+func loginOrReuseKubeconfig(config ConnectionConfig) (client.Client, kubernetes.Interface, error) {
+	if config.InCluster {
+		restConfig, err := rest.InClusterConfig()
 		if err != nil {
 			return nil, nil, microerror.Mask(err)
 		}
-		// now retry
-		ctrlClient, clientSet, err = getK8sClientFromKubeconfig(contextNameFromCluster(cluster))
+
+		return newK8sClient(config.stdout(), restConfig, fmt.Sprintf("%s (in-cluster)", config.Name))
+	}
+
+	kubeconfigFile, err := kubeconfigPath(config.Kubeconfig)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+
+	contextName := config.contextName()
+	exists, err := contextExists(kubeconfigFile, contextName)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+
+	if !exists && config.LoginProvider != nil {
+		err = config.LoginProvider.Login(config.stdout(), config.Name, kubeconfigFile, contextName)
 		if err != nil {
 			return nil, nil, microerror.Mask(err)
 		}
-	} else if err != nil {
-		return nil, nil, microerror.Mask(err)
-	}
-	return ctrlClient, clientSet, nil
-}
 
-func getK8sClientFromKubeconfig(contextName string) (client.Client, kubernetes.Interface, error) {
-	kubeconfigFile := os.Getenv("KUBECONFIG")
-	if kubeconfigFile == "" {
-		home, err := os.UserHomeDir()
+		exists, err = contextExists(kubeconfigFile, contextName)
 		if err != nil {
 			return nil, nil, microerror.Mask(err)
 		}
-		kubeconfigFile = fmt.Sprintf("%s/.kube/config", home)
 	}
 
+	if !exists {
+		return nil, nil, microerror.Maskf(contextNotFound, "Context %s does not exist in %s", contextName, kubeconfigFile)
+	}
+
+	return getK8sClientFromKubeconfig(config.stdout(), kubeconfigFile, contextName)
+}
+
+func getK8sClientFromKubeconfig(out io.Writer, kubeconfigFile string, contextName string) (client.Client, kubernetes.Interface, error) {
 	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigFile},
 		&clientcmd.ConfigOverrides{
@@ -220,6 +272,10 @@ func getK8sClientFromKubeconfig(contextName string) (client.Client, kubernetes.I
 		return nil, nil, microerror.Mask(err)
 	}
 
+	return newK8sClient(out, config, contextName)
+}
+
+func newK8sClient(out io.Writer, config *rest.Config, description string) (client.Client, kubernetes.Interface, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, nil, microerror.Mask(err)
@@ -228,7 +284,7 @@ func getK8sClientFromKubeconfig(contextName string) (client.Client, kubernetes.I
 	if err != nil {
 		return nil, nil, microerror.Mask(err)
 	}
-	fmt.Printf("Connected to %s, k8s server version %s\n", contextName, v.String())
+	_, _ = fmt.Fprintf(out, "Connected to %s, k8s server version %s\n", description, v.String())
 
 	ctrlClient, err := client.New(config, client.Options{Scheme: scheme})
 	if err != nil {
@@ -238,21 +294,6 @@ func getK8sClientFromKubeconfig(contextName string) (client.Client, kubernetes.I
 	return ctrlClient, clientset, nil
 }
 
-// LoginIntoCluster will login into cluster by executing opsctl login command
-func loginIntoCLuster(cluster []string) error {
-	args := append([]string{"login", "--no-cache"}, cluster...)
-	c := exec.Command("opsctl", args...) //nolint:gosec
-
-	c.Stderr = os.Stderr
-	c.Stdin = os.Stdin
-
-	err := c.Run()
-	if err != nil {
-		return microerror.Mask(err)
-	}
-	return nil
-}
-
 func contextNameFromCluster(cluster []string) string {
 	if len(cluster) == 1 {
 		return fmt.Sprintf("gs-%s", cluster[0])
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -14,7 +15,10 @@ func TestAppYamlFileWithOverride(t *testing.T) {
 		},
 	}
 
-	res := c.AppYamlFile("test.yaml")
+	res, err := c.AppYamlFile("test.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
 	dir, _ := os.Getwd()
 	want := fmt.Sprintf("%s/test.yaml", dir)
 
@@ -31,7 +35,10 @@ func TestAppYamlFileWithEmpty(t *testing.T) {
 		},
 	}
 
-	res := c.AppYamlFile("")
+	res, err := c.AppYamlFile("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
 	dir, _ := os.Getwd()
 	want := fmt.Sprintf("%s/%s-%s-apps.yaml", dir, c.SrcMC.Name, c.WcName)
 
@@ -39,3 +46,28 @@ func TestAppYamlFileWithEmpty(t *testing.T) {
 		t.Fatalf(`Result: %s, want %s`, res, want)
 	}
 }
+
+// diff only connects to the destination MC, the default dump file name can
+// not be used there
+func TestAppYamlFileWithoutSourceMC(t *testing.T) {
+	c := Cluster{
+		WcName: "foo",
+	}
+
+	_, err := c.AppYamlFile("")
+	if !errors.Is(err, dumpFileNotSet) {
+		t.Fatalf("Error is wrong. Is: %v; Want: %s", err, dumpFileNotSet)
+	}
+}
+
+func TestConnectionConfigContextName(t *testing.T) {
+	res := ConnectionConfig{Name: "gauss"}.contextName()
+	if res != "gs-gauss" {
+		t.Fatalf(`Result: %s, want %s`, res, "gs-gauss")
+	}
+
+	res = ConnectionConfig{Name: "gauss", Context: "ci-gauss"}.contextName()
+	if res != "ci-gauss" {
+		t.Fatalf(`Result: %s, want %s`, res, "ci-gauss")
+	}
+}
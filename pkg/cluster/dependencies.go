@@ -0,0 +1,113 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// dependsOnAnnotation lists the names of the apps in the same namespace which
+// app-operator installs before the annotated app, separated by commas.
+const dependsOnAnnotation = "app-operator.giantswarm.io/depends-on"
+
+// appDependencies returns the names of the apps the app depends on.
+func appDependencies(annotations map[string]string) []string {
+	var dependencies []string
+
+	for _, name := range strings.Split(annotations[dependsOnAnnotation], ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			dependencies = append(dependencies, name)
+		}
+	}
+
+	return dependencies
+}
+
+// migrateAnnotations returns a copy of the annotations with the dependencies
+// renamed like the apps they point to.
+func (c *Cluster) migrateAnnotations(annotations map[string]string) map[string]string {
+	newAnnotations := copyMetadata(annotations)
+
+	dependencies := appDependencies(annotations)
+	if len(dependencies) == 0 {
+		return newAnnotations
+	}
+
+	for i, name := range dependencies {
+		dependencies[i] = c.appName(name)
+	}
+	newAnnotations[dependsOnAnnotation] = strings.Join(dependencies, ",")
+
+	return newAnnotations
+}
+
+// applyOrder returns the objects in the order they are applied. Config comes
+// first, so apps find it when they are created. Apps follow in the order of
+// their dependencies, apps without dependencies between them keep their
+// order. Dependencies on apps which are not part of objects are ignored.
+func applyOrder(objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	var apps, ordered []*unstructured.Unstructured
+
+	for _, obj := range objects {
+		if obj.GetKind() == "App" {
+			apps = append(apps, obj)
+		} else {
+			ordered = append(ordered, obj)
+		}
+	}
+
+	index := map[string]int{}
+	for i, obj := range apps {
+		index[appKey(obj.GetNamespace(), obj.GetName())] = i
+	}
+
+	// dependents[i] lists the apps which wait for app i
+	dependents := make([][]int, len(apps))
+	pending := make([]int, len(apps))
+	for i, obj := range apps {
+		for _, name := range appDependencies(obj.GetAnnotations()) {
+			j, found := index[appKey(obj.GetNamespace(), name)]
+			if !found || j == i {
+				continue
+			}
+			dependents[j] = append(dependents[j], i)
+			pending[i]++
+		}
+	}
+
+	applied := make([]bool, len(apps))
+	for len(ordered) < len(objects) {
+		next := -1
+		for i := range apps {
+			if !applied[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			var cycle []string
+			for i, obj := range apps {
+				if !applied[i] {
+					cycle = append(cycle, appKey(obj.GetNamespace(), obj.GetName()))
+				}
+			}
+			return nil, microerror.Maskf(dependencyCycle, "Apps depend on each other in a cycle: %s", strings.Join(cycle, ", "))
+		}
+
+		applied[next] = true
+		ordered = append(ordered, apps[next])
+		for _, i := range dependents[next] {
+			pending[i]--
+		}
+	}
+
+	return ordered, nil
+}
+
+func appKey(namespace string, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
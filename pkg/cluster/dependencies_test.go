@@ -0,0 +1,131 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMigrateAnnotations(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cluster Cluster
+		want    string
+	}{
+		{
+			name:    "vintage",
+			cluster: Cluster{WcName: "wc1"},
+			want:    "wc1-prometheus-operator-crd,wc1-cert-manager",
+		},
+		{
+			name:    "vintage renamed",
+			cluster: Cluster{WcName: "wc1", DstWcName: "wc2"},
+			want:    "wc2-prometheus-operator-crd,wc2-cert-manager",
+		},
+		{
+			name:    "capi",
+			cluster: Cluster{WcName: "wc1", Mode: ModeCAPI},
+			want:    "prometheus-operator-crd,wc1-cert-manager",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			annotations := map[string]string{
+				dependsOnAnnotation: "prometheus-operator-crd, wc1-cert-manager",
+				"foo":               "bar",
+			}
+
+			migrated := tc.cluster.migrateAnnotations(annotations)
+
+			if migrated[dependsOnAnnotation] != tc.want {
+				t.Fatalf("Dependencies are wrong. Is: %s; Want: %s", migrated[dependsOnAnnotation], tc.want)
+			}
+			if migrated["foo"] != "bar" {
+				t.Fatalf("Annotations not preserved. Is: %v", migrated)
+			}
+			if annotations[dependsOnAnnotation] != "prometheus-operator-crd, wc1-cert-manager" {
+				t.Fatalf("Source annotations modified. Is: %v", annotations)
+			}
+		})
+	}
+}
+
+func newOrderObject(kind string, name string, dependsOn string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace("org-foo")
+	if dependsOn != "" {
+		obj.SetAnnotations(map[string]string{dependsOnAnnotation: dependsOn})
+	}
+
+	return obj
+}
+
+func TestApplyOrder(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		newOrderObject("App", "loki", "promtail,grafana"),
+		newOrderObject("ConfigMap", "loki-user-values", ""),
+		newOrderObject("App", "promtail", "prometheus-operator-crd"),
+		newOrderObject("App", "grafana", "missing"),
+		newOrderObject("App", "prometheus-operator-crd", ""),
+		newOrderObject("Secret", "grafana-user-secrets", ""),
+	}
+
+	ordered, err := applyOrder(objects)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	want := []string{"loki-user-values", "grafana-user-secrets", "grafana", "prometheus-operator-crd", "promtail", "loki"}
+	if len(ordered) != len(want) {
+		t.Fatalf("Number of objects is wrong. Is: %d; Want: %d", len(ordered), len(want))
+	}
+	for i := range want {
+		if ordered[i].GetName() != want[i] {
+			t.Fatalf("Object %d is wrong. Is: %s; Want: %s", i, ordered[i].GetName(), want[i])
+		}
+	}
+}
+
+func TestApplyOrderCycle(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		newOrderObject("App", "loki", "promtail"),
+		newOrderObject("App", "promtail", "grafana"),
+		newOrderObject("App", "grafana", "loki"),
+		newOrderObject("App", "unrelated", ""),
+	}
+
+	_, err := applyOrder(objects)
+	if !errors.Is(err, dependencyCycle) {
+		t.Fatalf("Cycle should be rejected. Is: %v", err)
+	}
+}
+
+func TestRollbackOrder(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		newOrderObject("ConfigMap", "loki-user-values", ""),
+		newOrderObject("App", "loki", "promtail"),
+		newOrderObject("App", "promtail", "prometheus-operator-crd"),
+		newOrderObject("App", "prometheus-operator-crd", ""),
+		newOrderObject("Secret", "promtail-user-secrets", ""),
+	}
+
+	ordered, err := rollbackOrder(objects)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// dependents are deleted before their dependencies, config last
+	want := []string{"loki", "promtail", "prometheus-operator-crd", "promtail-user-secrets", "loki-user-values"}
+	if len(ordered) != len(want) {
+		t.Fatalf("Number of objects is wrong. Is: %d; Want: %d", len(ordered), len(want))
+	}
+	for i := range want {
+		if ordered[i].GetName() != want[i] {
+			t.Fatalf("Object %d is wrong. Is: %s; Want: %s", i, ordered[i].GetName(), want[i])
+		}
+	}
+}
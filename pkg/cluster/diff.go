@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// DiffResult describes how an object of the dump file relates to the live
+// object on the destination MC.
+type DiffResult string
+
+const (
+	DiffResultNew       DiffResult = "new"
+	DiffResultChanged   DiffResult = "changed"
+	DiffResultIdentical DiffResult = "identical"
+)
+
+const (
+	redactedValue        = "<redacted>"
+	redactedChangedValue = "<redacted, changed>"
+)
+
+// ObjectDiff is the outcome of comparing a single object of the dump file
+// against the destination MC.
+type ObjectDiff struct {
+	Kind      string     `json:"kind"`
+	Name      string     `json:"name"`
+	Namespace string     `json:"namespace"`
+	Result    DiffResult `json:"result"`
+
+	// Diff is a unified diff between the live and the migrated object. Secret
+	// values are redacted. It is empty for identical objects.
+	Diff string `json:"diff,omitempty"`
+}
+
+// DiffCAPIApps compares every object of the dump file against the live
+// object on the destination MC. It runs a server-side dry-run apply, so
+// defaulting done by the API server does not show up as a change. It stops
+// before the next object when ctx is cancelled.
+func (c *Cluster) DiffCAPIApps(ctx context.Context, filename string) ([]ObjectDiff, error) {
+	_, objects, err := c.loadBundle(filename)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var diffs []ObjectDiff
+	for _, obj := range objects {
+		if ctx.Err() != nil {
+			return nil, microerror.Mask(ctx.Err())
+		}
+
+		diff, err := diffObject(ctx, c.DstMC.KubernetesClient, obj)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+func diffObject(ctx context.Context, k8sClient client.Client, obj *unstructured.Unstructured) (ObjectDiff, error) {
+	result := ObjectDiff{
+		Kind:      obj.GetKind(),
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+	}
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(obj.GroupVersionKind())
+
+	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), live)
+	if errors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		return ObjectDiff{}, microerror.Mask(err)
+	}
+
+	merged := newApplyPatch(obj, live)
+
+	err = k8sClient.Patch(ctx, merged, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership, client.DryRunAll)
+	if err != nil {
+		return ObjectDiff{}, microerror.Mask(err)
+	}
+
+	liveObject := normalizeForDiff(live)
+	mergedObject := normalizeForDiff(merged)
+
+	switch {
+	case live == nil:
+		result.Result = DiffResultNew
+	case equality.Semantic.DeepEqual(liveObject, mergedObject):
+		result.Result = DiffResultIdentical
+		return result, nil
+	default:
+		result.Result = DiffResultChanged
+	}
+
+	if obj.GetKind() == "Secret" {
+		redactSecretData(mergedObject, liveObject)
+		redactSecretData(liveObject, nil)
+	}
+
+	result.Diff, err = unifiedDiff(liveObject, mergedObject, fmt.Sprintf("%s/%s/%s", strings.ToLower(result.Kind), result.Namespace, result.Name))
+	if err != nil {
+		return ObjectDiff{}, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+// normalizeForDiff drops status and server-managed metadata, which would
+// otherwise show up in every diff.
+func normalizeForDiff(obj *unstructured.Unstructured) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+
+	normalized := obj.DeepCopy()
+	delete(normalized.Object, "status")
+
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(normalized.Object, "metadata", field)
+	}
+
+	return normalized.Object
+}
+
+// redactSecretData replaces all secret values. Values which differ from the
+// ones in other are marked, so the diff still shows which keys change.
+func redactSecretData(obj map[string]interface{}, other map[string]interface{}) {
+	data, found, _ := unstructured.NestedMap(obj, "data")
+	if !found {
+		return
+	}
+
+	otherData, _, _ := unstructured.NestedMap(other, "data")
+
+	for key, value := range data {
+		if other != nil && otherData[key] != value {
+			data[key] = redactedChangedValue
+		} else {
+			data[key] = redactedValue
+		}
+	}
+
+	_ = unstructured.SetNestedMap(obj, data, "data")
+}
+
+func unifiedDiff(live map[string]interface{}, migrated map[string]interface{}, name string) (string, error) {
+	var liveYaml, migratedYaml []byte
+	var err error
+
+	if live != nil {
+		liveYaml, err = k8syaml.Marshal(live)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+	}
+
+	migratedYaml, err = k8syaml.Marshal(migrated)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	var out bytes.Buffer
+	err = difflib.WriteUnifiedDiff(&out, difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(liveYaml)),
+		B:        difflib.SplitLines(string(migratedYaml)),
+		FromFile: "live/" + name,
+		ToFile:   "migrated/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return out.String(), nil
+}
@@ -0,0 +1,126 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/backoff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiffCAPIApps(t *testing.T) {
+	const wcName = "cabbage01"
+	const orgNamespace = "org-capa-migration-testing"
+
+	dump := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cabbage01-new
+  namespace: org-capa-migration-testing
+data:
+  values: "foo: bar"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cabbage01-same
+  namespace: org-capa-migration-testing
+data:
+  values: "foo: bar"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: cabbage01-changed
+  namespace: org-capa-migration-testing
+data:
+  password: aHVudGVyMw==
+  username: YWRtaW4=
+---
+`
+
+	k8sClient := newApplyFakeClient(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-same", Namespace: orgNamespace},
+			Data:       map[string]string{"values": "foo: bar"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-changed", Namespace: orgNamespace},
+			Data:       map[string][]byte{"password": []byte("hunter2"), "username": []byte("admin")},
+		},
+	)
+
+	c := Cluster{
+		WcName:       wcName,
+		OrgNamespace: orgNamespace,
+		SrcMC:        &ManagementCluster{Name: "foo"},
+		DstMC:        &ManagementCluster{Name: "bar", KubernetesClient: k8sClient},
+		BackOff:      backoff.NewMaxRetries(0, 0),
+	}
+
+	diffs, err := c.DiffCAPIApps(context.TODO(), writeDumpFile(t, dump))
+	if err != nil {
+		t.Fatalf("Diff failed: %s", err)
+	}
+
+	want := map[string]DiffResult{
+		"cabbage01-new":     DiffResultNew,
+		"cabbage01-same":    DiffResultIdentical,
+		"cabbage01-changed": DiffResultChanged,
+	}
+
+	if len(diffs) != len(want) {
+		t.Fatalf("Diffed objects not correct; Is: %d; Want: %d", len(diffs), len(want))
+	}
+
+	for _, diff := range diffs {
+		if want[diff.Name] != diff.Result {
+			t.Fatalf("Diff result of %s not correct; Is: %s; Want: %s", diff.Name, diff.Result, want[diff.Name])
+		}
+
+		if diff.Result == DiffResultIdentical && diff.Diff != "" {
+			t.Fatalf("Identical object %s should not have a diff: %s", diff.Name, diff.Diff)
+		}
+
+		if diff.Result == DiffResultChanged {
+			if strings.Contains(diff.Diff, "aHVudGVy") {
+				t.Fatalf("Secret values are not redacted: %s", diff.Diff)
+			}
+
+			if !strings.Contains(diff.Diff, "+  password: "+redactedChangedValue) {
+				t.Fatalf("Changed secret key is not marked: %s", diff.Diff)
+			}
+
+			if strings.Contains(diff.Diff, "+  username") {
+				t.Fatalf("Unchanged secret key shows up as changed: %s", diff.Diff)
+			}
+		}
+	}
+
+	// dry-run must not create anything
+	diffs, err = c.DiffCAPIApps(context.TODO(), writeDumpFile(t, dump))
+	if err != nil {
+		t.Fatalf("Diff failed: %s", err)
+	}
+	if diffs[0].Result != DiffResultNew {
+		t.Fatalf("Diff must not create objects on the destination MC")
+	}
+}
+
+// diff only connects to the destination MC, without a dump file it must fail
+// instead of guessing the file name from the source MC
+func TestDiffCAPIAppsWithoutDumpFile(t *testing.T) {
+	c := Cluster{
+		WcName:       "cabbage01",
+		OrgNamespace: "org-capa-migration-testing",
+		DstMC:        &ManagementCluster{Name: "bar", KubernetesClient: newApplyFakeClient()},
+	}
+
+	_, err := c.DiffCAPIApps(context.TODO(), "")
+	if !errors.Is(err, dumpFileNotSet) {
+		t.Fatalf("Error is wrong. Is: %v; Want: %s", err, dumpFileNotSet)
+	}
+}
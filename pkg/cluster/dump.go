@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/fatih/color"
 	"github.com/giantswarm/microerror"
 	"golang.org/x/net/context"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -14,7 +15,11 @@ import (
 	//  apps "github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	applicationv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
 )
 
 const (
@@ -29,7 +34,30 @@ func (c *Cluster) DumpApps(f *os.File) error {
 		return microerror.Mask(err)
 	}
 
-	for _, obj := range yaml {
+	if c.EncryptionKey != nil {
+		for i, obj := range yaml {
+			if !isSecretYaml(obj) {
+				continue
+			}
+
+			yaml[i], err = encryptSecretYaml(obj, c.EncryptionKey)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	manifest, err := c.newBundleManifest(yaml)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	header, err := k8syaml.Marshal(manifest)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, obj := range append([][]byte{header}, yaml...) {
 		if _, err := fmt.Fprintf(f, "%s---\n", obj); err != nil {
 			return microerror.Mask(err)
 		}
@@ -45,59 +73,93 @@ func (c *Cluster) shouldSkipConfigMapOrSecretMigration(configMapOrSecretName str
 	// Copying it would result in cluster-apps-operator not reconciling it.
 	// In the `app-migration-cli apply` subcommand, we even wait for the new
 	// config map / secret to be available in order to allow Apps to deploy correctly.
-	return configMapOrSecretName == fmt.Sprintf("%s-cluster-values", c.WcName)
+	return configMapOrSecretName == apps.ClusterValuesName(c.WcName)
 }
 
 func (c *Cluster) migrateApps() ([][]byte, error) {
 
 	var yaml [][]byte
 
+	// config shared by several apps is written once
+	migratedConfig := map[string]bool{}
+	appendConfig := func(obj AppExtraConfig) {
+		key := strings.Join([]string{obj.Kind, obj.Namespace, obj.Name}, "/")
+		if migratedConfig[key] {
+			return
+		}
+		migratedConfig[key] = true
+
+		yaml = append(yaml, obj.Yaml)
+	}
+
 	for _, application := range c.Apps {
 		// 	DefaultingEnabled          bool
 		// 	UseClusterValuesConfig     bool
 
 		// todo: app operator version; does it impact the migration?
 		// todo: how to deal with ExtraLabels and Extrannotations?
+		labels, annotations, err := c.migrateMetadata("App", application.GetLabels(), application.GetAnnotations())
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		catalog, version := c.upgradeApp(application)
+
 		newApp := app.Config{
 			AppName:          application.Name,
-			Catalog:          application.Spec.Catalog,
-			Cluster:          c.WcName,
+			Catalog:          catalog,
+			Cluster:          c.DestinationWcName(),
 			InCluster:        application.Spec.KubeConfig.InCluster,
 			Name:             application.Spec.Name,
 			Namespace:        application.Spec.Namespace,
-			Version:          application.Spec.Version,
-			ExtraLabels:      application.GetLabels(),
-			ExtraAnnotations: application.GetAnnotations(),
+			Version:          version,
+			ExtraLabels:      labels,
+			ExtraAnnotations: annotations,
 			Organization:     organizationFromNamespace(c.OrgNamespace),
 		}
 
-		// make sure we trim the clustername if it somehow was prefixed on the app
-		appName := strings.TrimPrefix(application.GetName(), c.WcName)
-		// in case we trimmed the clustername, we might need to trim the trailing dash
-		// now as well.
-		appName = strings.TrimPrefix(appName, "-")
-		// now prefix the app with the wcName
-		newApp.AppName = fmt.Sprintf("%s-%s", c.WcName, appName)
+		newApp.AppName = c.appName(application.GetName())
 
 		// apps on the MC should go to the org namespace
 		if application.Spec.KubeConfig.InCluster {
 			newApp.Namespace = c.OrgNamespace
 		}
 
-		if application.Spec.Config.ConfigMap.Name == fmt.Sprintf("%s-cluster-values", c.WcName) {
+		if application.Spec.Config.ConfigMap.Name == apps.ClusterValuesName(c.WcName) {
 			newApp.UseClusterValuesConfig = true
 		}
 
+		appConfig, configObjects, err := c.migrateAppSpecConfig(application, newApp.Organization)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		for _, obj := range configObjects {
+			appendConfig(obj)
+		}
+
 		if application.Spec.ExtraConfigs != nil {
 			for _, extraConfig := range application.Spec.ExtraConfigs {
-				if (strings.ToLower(extraConfig.Kind) == configmapType || strings.ToLower(extraConfig.Kind) == secretType) && c.shouldSkipConfigMapOrSecretMigration(extraConfig.Name) {
+				isConfigObject := strings.ToLower(extraConfig.Kind) == configmapType || strings.ToLower(extraConfig.Kind) == secretType
+
+				// config created with the WC is not migrated, apps of CAPI
+				// clusters keep referencing it on the destination MC
+				if name, found := c.clusterReference(extraConfig.Name); isConfigObject && found && c.IsCAPI() {
+					newApp.ExtraConfigs = append(newApp.ExtraConfigs, applicationv1alpha1.AppExtraConfig{
+						Kind:      extraConfig.Kind,
+						Name:      name,
+						Namespace: c.OrgNamespace,
+						Priority:  extraConfig.Priority,
+					})
 					continue
 				}
 
-				obj, err := migrateAppConfigObject(
-					c.SrcMC.KubernetesClient,
+				if isConfigObject && c.shouldSkipConfigMapOrSecretMigration(extraConfig.Name) {
+					continue
+				}
+
+				obj, err := c.migrateAppConfigObject(
 					strings.ToLower(extraConfig.Kind),
-					c.WcName,
+					c.configObjectName(extraConfig.Name),
 					extraConfig.Name,
 					extraConfig.Namespace,
 					newApp.Organization)
@@ -113,7 +175,7 @@ func (c *Cluster) migrateApps() ([][]byte, error) {
 					Priority:  extraConfig.Priority,
 				})
 
-				yaml = append(yaml, obj.Yaml)
+				appendConfig(obj)
 			}
 		}
 
@@ -142,10 +204,9 @@ func (c *Cluster) migrateApps() ([][]byte, error) {
 		}
 
 		if application.Spec.UserConfig.ConfigMap.Name != "" && !c.shouldSkipConfigMapOrSecretMigration(application.Spec.UserConfig.ConfigMap.Name) {
-			configmap, err := migrateAppConfigObject(
-				c.SrcMC.KubernetesClient,
+			configmap, err := c.migrateAppConfigObject(
 				configmapType,
-				c.WcName,
+				c.configObjectName(application.Spec.UserConfig.ConfigMap.Name),
 				application.Spec.UserConfig.ConfigMap.Name,
 				application.Spec.UserConfig.ConfigMap.Namespace,
 				newApp.Organization)
@@ -156,16 +217,15 @@ func (c *Cluster) migrateApps() ([][]byte, error) {
 
 			newApp.UserConfigConfigMapName = configmap.Name
 
-			yaml = append(yaml, configmap.Yaml)
+			appendConfig(configmap)
 		}
 
 		if application.Spec.UserConfig.Secret.Name != "" && !c.shouldSkipConfigMapOrSecretMigration(application.Spec.UserConfig.Secret.Name) {
 			newApp.UserConfigSecretName = application.Spec.UserConfig.Secret.Name
 
-			secret, err := migrateAppConfigObject(
-				c.SrcMC.KubernetesClient,
+			secret, err := c.migrateAppConfigObject(
 				secretType,
-				c.WcName,
+				c.configObjectName(application.Spec.UserConfig.Secret.Name),
 				application.Spec.UserConfig.Secret.Name,
 				application.Spec.UserConfig.Secret.Namespace,
 				newApp.Organization)
@@ -176,24 +236,136 @@ func (c *Cluster) migrateApps() ([][]byte, error) {
 
 			newApp.UserConfigSecretName = secret.Name
 
-			yaml = append(yaml, secret.Yaml)
+			appendConfig(secret)
 		}
 
 		appYAML, err := app.NewAppCR(newApp)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
+
+		appYAML, err = setAppSpecConfig(appYAML, appConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
 		yaml = append(yaml, appYAML)
 	}
 
 	return yaml, nil
 }
 
+// migrateAppSpecConfig migrates the config map and secret of spec.config
+// like user config. References to the cluster values are kept and point to
+// the WC on the destination MC, a warning is recorded for every object which
+// does not exist. The migrated objects are returned for the dump file.
+func (c *Cluster) migrateAppSpecConfig(application applicationv1alpha1.App, organization string) (applicationv1alpha1.AppSpecConfig, []AppExtraConfig, error) {
+	var config applicationv1alpha1.AppSpecConfig
+	var objects []AppExtraConfig
+
+	configMap := application.Spec.Config.ConfigMap
+	// the cluster values config map is set by NewAppCR
+	if configMap.Name != "" && configMap.Name != apps.ClusterValuesName(c.WcName) {
+		obj, found, err := c.migrateReferencedConfigObject(application, configmapType, configMap.Name, configMap.Namespace, organization)
+		if err != nil {
+			return config, nil, microerror.Mask(err)
+		}
+		if found {
+			config.ConfigMap = applicationv1alpha1.AppSpecConfigConfigMap{Name: obj.Name, Namespace: obj.Namespace}
+		}
+		if obj.Yaml != nil {
+			objects = append(objects, obj)
+		}
+	}
+
+	secret := application.Spec.Config.Secret
+	if secret.Name != "" {
+		obj, found, err := c.migrateReferencedConfigObject(application, secretType, secret.Name, secret.Namespace, organization)
+		if err != nil {
+			return config, nil, microerror.Mask(err)
+		}
+		if found {
+			config.Secret = applicationv1alpha1.AppSpecConfigSecret{Name: obj.Name, Namespace: obj.Namespace}
+		}
+		if obj.Yaml != nil {
+			objects = append(objects, obj)
+		}
+	}
+
+	return config, objects, nil
+}
+
+// migrateReferencedConfigObject migrates a config map or secret referenced by
+// the app. Objects created with the WC are only referenced, their Yaml is
+// empty. found is false if the object does not exist.
+func (c *Cluster) migrateReferencedConfigObject(application applicationv1alpha1.App, resourceKind string, name string, namespace string, organization string) (AppExtraConfig, bool, error) {
+	if newName, found := c.clusterReference(name); found {
+		return AppExtraConfig{Kind: resourceKind, Name: newName, Namespace: c.OrgNamespace}, true, nil
+	}
+
+	obj, err := c.migrateAppConfigObject(
+		resourceKind,
+		c.configObjectName(name),
+		name,
+		namespace,
+		organization)
+
+	if apierrors.IsNotFound(err) {
+		c.warn("%s %s/%s referenced by spec.config of app %s/%s not found, it is not migrated", resourceKind, namespace, name, application.Namespace, application.Name)
+		return AppExtraConfig{}, false, nil
+	} else if err != nil {
+		return AppExtraConfig{}, false, microerror.Mask(err)
+	}
+
+	return obj, true, nil
+}
+
+// setAppSpecConfig sets the config map and secret of spec.config on the app
+// yaml written by NewAppCR. Empty references are left untouched.
+func setAppSpecConfig(appYAML []byte, config applicationv1alpha1.AppSpecConfig) ([]byte, error) {
+	if config.ConfigMap.Name == "" && config.Secret.Name == "" {
+		return appYAML, nil
+	}
+
+	obj := map[string]interface{}{}
+	err := k8syaml.Unmarshal(appYAML, &obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if config.ConfigMap.Name != "" {
+		err = unstructured.SetNestedStringMap(obj, map[string]string{"name": config.ConfigMap.Name, "namespace": config.ConfigMap.Namespace}, "spec", "config", "configMap")
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	if config.Secret.Name != "" {
+		err = unstructured.SetNestedStringMap(obj, map[string]string{"name": config.Secret.Name, "namespace": config.Secret.Namespace}, "spec", "config", "secret")
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	appYAML, err = k8syaml.Marshal(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return appYAML, nil
+}
+
+// warn prints a warning and records it for the result of the command.
+func (c *Cluster) warn(format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	_, _ = color.New(color.FgRed).Fprintf(c.stdout(), "⚠  %s\n", warning)
+	c.Warnings = append(c.Warnings, warning)
+}
+
 func organizationFromNamespace(namespace string) string {
 	return strings.TrimPrefix(namespace, "org-")
 }
 
-func migrateAppConfigObject(k8sClient client.Client, resourceKind string, clusterName string, resourceName string, namespace string, organization string) (AppExtraConfig, error) {
+func (c *Cluster) migrateAppConfigObject(resourceKind string, name string, resourceName string, namespace string, organization string) (AppExtraConfig, error) {
 
 	var config AppExtraConfig
 
@@ -206,17 +378,14 @@ func migrateAppConfigObject(k8sClient client.Client, resourceKind string, cluste
 		config.Namespace = namespace
 	}
 
-	// make sure we trim the clustername if it somehow was prefixed on the app
-	name := strings.TrimPrefix(resourceName, clusterName)
-	name = strings.TrimPrefix(name, "-")
-	config.Name = fmt.Sprintf("%s-%s", clusterName, name)
+	config.Name = name
 
 	switch resourceKind {
 	case secretType:
 		var secret corev1.Secret
 		config.Kind = secretType
 
-		err := k8sClient.Get(context.TODO(), client.ObjectKey{
+		err := c.SrcMC.KubernetesClient.Get(context.TODO(), client.ObjectKey{
 			Name:      resourceName,
 			Namespace: namespace,
 		}, &secret)
@@ -225,6 +394,16 @@ func migrateAppConfigObject(k8sClient client.Client, resourceKind string, cluste
 			return AppExtraConfig{}, microerror.Mask(err)
 		}
 
+		labels, annotations, err := c.migrateMetadata("Secret", secret.GetLabels(), secret.GetAnnotations())
+		if err != nil {
+			return AppExtraConfig{}, microerror.Mask(err)
+		}
+
+		secretData, err := c.transformSecretValues(resourceName, secret.Data)
+		if err != nil {
+			return AppExtraConfig{}, microerror.Mask(err)
+		}
+
 		newSecret := &corev1.Secret{
 			TypeMeta: metav1.TypeMeta{
 				Kind:       "Secret",
@@ -233,10 +412,10 @@ func migrateAppConfigObject(k8sClient client.Client, resourceKind string, cluste
 			ObjectMeta: metav1.ObjectMeta{
 				Name:        config.Name,
 				Namespace:   config.Namespace,
-				Labels:      secret.GetLabels(),
-				Annotations: secret.GetAnnotations(),
+				Labels:      labels,
+				Annotations: annotations,
 			},
-			Data: secret.Data,
+			Data: secretData,
 		}
 		config.Yaml, err = k8syaml.Marshal(newSecret)
 
@@ -248,7 +427,7 @@ func migrateAppConfigObject(k8sClient client.Client, resourceKind string, cluste
 		var cm corev1.ConfigMap
 		config.Kind = configmapType
 
-		err := k8sClient.Get(context.TODO(), client.ObjectKey{
+		err := c.SrcMC.KubernetesClient.Get(context.TODO(), client.ObjectKey{
 			Name:      resourceName,
 			Namespace: namespace,
 		}, &cm)
@@ -257,6 +436,16 @@ func migrateAppConfigObject(k8sClient client.Client, resourceKind string, cluste
 			return AppExtraConfig{}, microerror.Mask(err)
 		}
 
+		labels, annotations, err := c.migrateMetadata("ConfigMap", cm.GetLabels(), cm.GetAnnotations())
+		if err != nil {
+			return AppExtraConfig{}, microerror.Mask(err)
+		}
+
+		cmData, err := c.transformValues("ConfigMap", resourceName, cm.Data)
+		if err != nil {
+			return AppExtraConfig{}, microerror.Mask(err)
+		}
+
 		newCm := &corev1.ConfigMap{
 			TypeMeta: metav1.TypeMeta{
 				Kind:       "ConfigMap",
@@ -265,10 +454,10 @@ func migrateAppConfigObject(k8sClient client.Client, resourceKind string, cluste
 			ObjectMeta: metav1.ObjectMeta{
 				Name:        config.Name,
 				Namespace:   config.Namespace,
-				Labels:      cm.GetLabels(),
-				Annotations: cm.GetAnnotations(),
+				Labels:      labels,
+				Annotations: annotations,
 			},
-			Data: cm.Data,
+			Data: cmData,
 		}
 
 		config.Yaml, err = k8syaml.Marshal(newCm)
@@ -427,3 +427,331 @@ func TestExtraConfigSecretMigration(t *testing.T) {
 	}
 
 }
+
+func TestDumpCAPIMode(t *testing.T) {
+	var migratedApp app.App
+	var migratedConfigMap corev1.ConfigMap
+
+	orgNamespace := "org-capa-migration-testing"
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "loki-user-values",
+			Namespace: orgNamespace,
+		},
+		Data: map[string]string{"values": "foo: bar"},
+	}
+
+	c := Cluster{
+		WcName:       "cabbage01",
+		DstWcName:    "cabbage02",
+		OrgNamespace: orgNamespace,
+		Mode:         ModeCAPI,
+		SrcMC: &ManagementCluster{
+			Name:             "bar",
+			KubernetesClient: fake.NewFakeClient(configMap),
+		},
+		Apps: []app.App{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "loki",
+					Namespace: orgNamespace,
+					Labels:    map[string]string{"giantswarm.io/cluster": "cabbage01", "foo": "bar"},
+				},
+				Spec: app.AppSpec{
+					Name:      "loki",
+					Namespace: "loki",
+					Version:   "0.1.0",
+					Catalog:   "giantswarm",
+					Config: app.AppSpecConfig{
+						ConfigMap: app.AppSpecConfigConfigMap{Name: "cabbage01-cluster-values", Namespace: orgNamespace},
+					},
+					KubeConfig: app.AppSpecKubeConfig{
+						Secret: app.AppSpecKubeConfigSecret{Name: "cabbage01-kubeconfig", Namespace: orgNamespace},
+					},
+					UserConfig: app.AppSpecUserConfig{
+						ConfigMap: app.AppSpecUserConfigConfigMap{Name: "loki-user-values", Namespace: orgNamespace},
+					},
+					ExtraConfigs: []app.AppExtraConfig{
+						{Kind: "secret", Name: "cabbage01-kubeconfig", Namespace: orgNamespace, Priority: 30},
+					},
+				},
+			},
+		},
+	}
+
+	yamlText, err := c.migrateApps()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(yamlText) != 2 {
+		t.Fatalf("Number of migrated objects is wrong. Is: %d; Want: %d", len(yamlText), 2)
+	}
+
+	err = yaml.Unmarshal(yamlText[0], &migratedConfigMap)
+	if err != nil {
+		t.Fatalf(`Could not unmarshal yaml: %s`, err)
+	}
+
+	err = yaml.Unmarshal(yamlText[1], &migratedApp)
+	if err != nil {
+		t.Fatalf(`Could not unmarshal yaml: %s`, err)
+	}
+
+	// names of apps and their config are preserved
+	if migratedApp.Name != "loki" {
+		t.Fatalf(`App name not preserved; Is: %s; Want: %s`, migratedApp.Name, "loki")
+	}
+	if migratedConfigMap.Name != "loki-user-values" || migratedApp.Spec.UserConfig.ConfigMap.Name != "loki-user-values" {
+		t.Fatalf(`User config name not preserved; Is: %s; Want: %s`, migratedConfigMap.Name, "loki-user-values")
+	}
+
+	// references to the WC point to the destination WC
+	if migratedApp.Labels["giantswarm.io/cluster"] != "cabbage02" {
+		t.Fatalf(`Cluster label not remapped; Is: %s; Want: %s`, migratedApp.Labels["giantswarm.io/cluster"], "cabbage02")
+	}
+	if migratedApp.Labels["foo"] != "bar" {
+		t.Fatalf(`Labels not preserved; Is: %v`, migratedApp.Labels)
+	}
+	if migratedApp.Spec.KubeConfig.Secret.Name != "cabbage02-kubeconfig" {
+		t.Fatalf(`Kubeconfig not remapped; Is: %s; Want: %s`, migratedApp.Spec.KubeConfig.Secret.Name, "cabbage02-kubeconfig")
+	}
+	if migratedApp.Spec.Config.ConfigMap.Name != "cabbage02-cluster-values" {
+		t.Fatalf(`Cluster values not remapped; Is: %s; Want: %s`, migratedApp.Spec.Config.ConfigMap.Name, "cabbage02-cluster-values")
+	}
+	if len(migratedApp.Spec.ExtraConfigs) != 1 || migratedApp.Spec.ExtraConfigs[0].Name != "cabbage02-kubeconfig" || migratedApp.Spec.ExtraConfigs[0].Priority != 30 {
+		t.Fatalf(`ExtraConfig referencing the kubeconfig not remapped; Is: %v`, migratedApp.Spec.ExtraConfigs)
+	}
+
+	// the source app is not modified
+	if c.Apps[0].Labels["giantswarm.io/cluster"] != "cabbage01" {
+		t.Fatalf(`Source app labels modified; Is: %s; Want: %s`, c.Apps[0].Labels["giantswarm.io/cluster"], "cabbage01")
+	}
+}
+
+func TestDumpRenamedWC(t *testing.T) {
+	var migratedApp app.App
+	var migratedConfigMap corev1.ConfigMap
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cabbage01-loki-user-values",
+			Namespace: "cabbage01",
+		},
+	}
+
+	c := Cluster{
+		WcName:       "cabbage01",
+		DstWcName:    "cabbage02",
+		OrgNamespace: "org-capa-migration-testing",
+		SrcMC: &ManagementCluster{
+			Name:             "bar",
+			KubernetesClient: fake.NewFakeClient(configMap),
+		},
+		Apps: []app.App{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cabbage01-loki",
+					Namespace: "cabbage01",
+					Labels:    map[string]string{"giantswarm.io/cluster": "cabbage01"},
+				},
+				Spec: app.AppSpec{
+					Name:      "loki",
+					Namespace: "loki",
+					Version:   "0.1.0",
+					Catalog:   "giantswarm",
+					Config: app.AppSpecConfig{
+						ConfigMap: app.AppSpecConfigConfigMap{Name: "cabbage01-cluster-values", Namespace: "cabbage01"},
+					},
+					UserConfig: app.AppSpecUserConfig{
+						ConfigMap: app.AppSpecUserConfigConfigMap{Name: "cabbage01-loki-user-values", Namespace: "cabbage01"},
+					},
+				},
+			},
+		},
+	}
+
+	yamlText, err := c.migrateApps()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	err = yaml.Unmarshal(yamlText[0], &migratedConfigMap)
+	if err != nil {
+		t.Fatalf(`Could not unmarshal yaml: %s`, err)
+	}
+
+	err = yaml.Unmarshal(yamlText[1], &migratedApp)
+	if err != nil {
+		t.Fatalf(`Could not unmarshal yaml: %s`, err)
+	}
+
+	if migratedApp.Name != "cabbage02-loki" {
+		t.Fatalf(`App name not renamed; Is: %s; Want: %s`, migratedApp.Name, "cabbage02-loki")
+	}
+	if migratedConfigMap.Name != "cabbage02-loki-user-values" || migratedApp.Spec.UserConfig.ConfigMap.Name != "cabbage02-loki-user-values" {
+		t.Fatalf(`User config not renamed; Is: %s; Want: %s`, migratedConfigMap.Name, "cabbage02-loki-user-values")
+	}
+	if migratedApp.Labels["giantswarm.io/cluster"] != "cabbage02" {
+		t.Fatalf(`Cluster label not renamed; Is: %s; Want: %s`, migratedApp.Labels["giantswarm.io/cluster"], "cabbage02")
+	}
+	if migratedApp.Spec.Config.ConfigMap.Name != "cabbage02-cluster-values" {
+		t.Fatalf(`Cluster values not renamed; Is: %s; Want: %s`, migratedApp.Spec.Config.ConfigMap.Name, "cabbage02-cluster-values")
+	}
+	if migratedApp.Spec.KubeConfig.Secret.Name != "cabbage02-kubeconfig" {
+		t.Fatalf(`Kubeconfig not renamed; Is: %s; Want: %s`, migratedApp.Spec.KubeConfig.Secret.Name, "cabbage02-kubeconfig")
+	}
+}
+
+func TestDumpSpecConfigMigration(t *testing.T) {
+	var migratedConfigMap corev1.ConfigMap
+	var migratedApp app.App
+
+	wcName := "cabbage01"
+	orgNamespace := "org-capa-migration-testing"
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "loki-catalog-values",
+			Namespace: wcName,
+		},
+		Data: map[string]string{"values": "foo: bar"},
+	}
+
+	c := Cluster{
+		WcName:       wcName,
+		OrgNamespace: orgNamespace,
+		SrcMC: &ManagementCluster{
+			Name:             "bar",
+			KubernetesClient: fake.NewFakeClient(configMap),
+		},
+		Apps: []app.App{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "loki",
+					Namespace: wcName,
+				},
+				Spec: app.AppSpec{
+					Name:      "loki",
+					Namespace: "loki",
+					Version:   "0.1.0",
+					Catalog:   "giantswarm",
+					Config: app.AppSpecConfig{
+						ConfigMap: app.AppSpecConfigConfigMap{Name: "loki-catalog-values", Namespace: wcName},
+						Secret:    app.AppSpecConfigSecret{Name: "missing", Namespace: wcName},
+					},
+				},
+			},
+		},
+	}
+
+	yamlText, err := c.migrateApps()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(yamlText) != 2 {
+		t.Fatalf("Number of migrated objects is wrong. Is: %d; Want: %d", len(yamlText), 2)
+	}
+
+	err = yaml.Unmarshal(yamlText[0], &migratedConfigMap)
+	if err != nil {
+		t.Fatalf(`Could not unmarshal yaml: %s`, err)
+	}
+
+	err = yaml.UnmarshalStrict(yamlText[1], &migratedApp)
+	if err != nil {
+		t.Fatalf(`Could not unmarshal yaml: %s`, err)
+	}
+
+	wantName := fmt.Sprintf("%s-loki-catalog-values", wcName)
+	if migratedConfigMap.Name != wantName || migratedConfigMap.Namespace != orgNamespace || migratedConfigMap.Data["values"] != "foo: bar" {
+		t.Fatalf(`Config map of spec.config not migrated; Is: %s/%s`, migratedConfigMap.Namespace, migratedConfigMap.Name)
+	}
+
+	if migratedApp.Spec.Config.ConfigMap.Name != wantName || migratedApp.Spec.Config.ConfigMap.Namespace != orgNamespace {
+		t.Fatalf(`Config map of spec.config not attached; Is: %s/%s; Want: %s/%s`, migratedApp.Spec.Config.ConfigMap.Namespace, migratedApp.Spec.Config.ConfigMap.Name, orgNamespace, wantName)
+	}
+
+	if migratedApp.Spec.Config.Secret.Name != "" {
+		t.Fatalf(`Missing secret of spec.config should not be attached; Is: %s`, migratedApp.Spec.Config.Secret.Name)
+	}
+
+	if len(c.Warnings) != 1 {
+		t.Fatalf("Number of warnings is wrong. Is: %d; Want: %d", len(c.Warnings), 1)
+	}
+}
+
+// Test config shared by several apps is migrated once
+func TestDumpSharedSpecConfigMigration(t *testing.T) {
+	wcName := "cabbage01"
+	orgNamespace := "org-capa-migration-testing"
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shared-catalog-values",
+			Namespace: wcName,
+		},
+		Data: map[string]string{"values": "foo: bar"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shared-catalog-secrets",
+			Namespace: wcName,
+		},
+		Data: map[string][]byte{"values": []byte("foo: bar")},
+	}
+
+	newApp := func(name string) app.App {
+		return app.App{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: wcName,
+			},
+			Spec: app.AppSpec{
+				Name:      name,
+				Namespace: name,
+				Version:   "0.1.0",
+				Catalog:   "giantswarm",
+				Config: app.AppSpecConfig{
+					ConfigMap: app.AppSpecConfigConfigMap{Name: "shared-catalog-values", Namespace: wcName},
+					Secret:    app.AppSpecConfigSecret{Name: "shared-catalog-secrets", Namespace: wcName},
+				},
+			},
+		}
+	}
+
+	c := Cluster{
+		WcName:       wcName,
+		OrgNamespace: orgNamespace,
+		SrcMC: &ManagementCluster{
+			Name:             "bar",
+			KubernetesClient: fake.NewFakeClient(configMap, secret),
+		},
+		Apps: []app.App{newApp("loki"), newApp("promtail")},
+	}
+
+	yamlText, err := c.migrateApps()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// config map, secret and both apps
+	if len(yamlText) != 4 {
+		t.Fatalf("Number of migrated objects is wrong. Is: %d; Want: %d", len(yamlText), 4)
+	}
+
+	wantConfigMap := fmt.Sprintf("%s-shared-catalog-values", wcName)
+	for _, obj := range yamlText[2:] {
+		var migratedApp app.App
+		err = yaml.UnmarshalStrict(obj, &migratedApp)
+		if err != nil {
+			t.Fatalf(`Could not unmarshal yaml: %s`, err)
+		}
+
+		if migratedApp.Spec.Config.ConfigMap.Name != wantConfigMap {
+			t.Fatalf(`Shared config map not attached to %s; Is: %s; Want: %s`, migratedApp.Name, migratedApp.Spec.Config.ConfigMap.Name, wantConfigMap)
+		}
+	}
+}
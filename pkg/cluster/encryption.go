@@ -0,0 +1,151 @@
+package cluster
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"filippo.io/age"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// encryptedAnnotation marks secrets in a dump file whose data values are
+	// encrypted. The annotation is removed again before the secret is applied.
+	encryptedAnnotation = "app-migration-cli.giantswarm.io/encrypted"
+
+	secretEncryptionAge = "age"
+)
+
+// LoadEncryptionKey reads an age identity file as generated by `age-keygen`.
+// The same key file is used to encrypt secrets in prepare and to decrypt
+// them in apply.
+func LoadEncryptionKey(filename string) (*age.X25519Identity, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, identity := range identities {
+		if key, ok := identity.(*age.X25519Identity); ok {
+			return key, nil
+		}
+	}
+
+	return nil, microerror.Maskf(invalidEncryptionKey, "No X25519 identity found in %s", filename)
+}
+
+// encryptSecretYaml encrypts every data value of a secret manifest. Keys stay
+// readable so the dump file can still be reviewed.
+func encryptSecretYaml(secretYaml []byte, key *age.X25519Identity) ([]byte, error) {
+	var secret corev1.Secret
+
+	err := k8syaml.Unmarshal(secretYaml, &secret)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for name, value := range secret.Data {
+		var buf bytes.Buffer
+
+		w, err := age.Encrypt(&buf, key.Recipient())
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if _, err := w.Write(value); err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		secret.Data[name] = buf.Bytes()
+	}
+
+	annotations := secret.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[encryptedAnnotation] = secretEncryptionAge
+	secret.SetAnnotations(annotations)
+
+	out, err := k8syaml.Marshal(&secret)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return out, nil
+}
+
+func isSecretYaml(objYaml []byte) bool {
+	var typeMeta metav1.TypeMeta
+
+	err := k8syaml.Unmarshal(objYaml, &typeMeta)
+	if err != nil {
+		return false
+	}
+
+	return typeMeta.Kind == "Secret"
+}
+
+func isEncryptedSecret(obj *unstructured.Unstructured) bool {
+	if obj.GetKind() != "Secret" {
+		return false
+	}
+
+	_, ok := obj.GetAnnotations()[encryptedAnnotation]
+	return ok
+}
+
+// decryptSecret decrypts the data values of an encrypted secret in place and
+// removes the encryption marker.
+func decryptSecret(obj *unstructured.Unstructured, key *age.X25519Identity) error {
+	if key == nil {
+		return microerror.Maskf(invalidEncryptionKey, "Secret %s/%s is encrypted but no encryption key was given", obj.GetNamespace(), obj.GetName())
+	}
+
+	var secret corev1.Secret
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &secret)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if secret.Annotations[encryptedAnnotation] != secretEncryptionAge {
+		return microerror.Maskf(invalidEncryptionKey, "Secret %s/%s uses unsupported encryption %q", obj.GetNamespace(), obj.GetName(), secret.Annotations[encryptedAnnotation])
+	}
+
+	for name, value := range secret.Data {
+		r, err := age.Decrypt(bytes.NewReader(value), key)
+		if err != nil {
+			return microerror.Maskf(invalidEncryptionKey, "Could not decrypt %s of secret %s/%s: %s", name, obj.GetNamespace(), obj.GetName(), err)
+		}
+
+		secret.Data[name], err = io.ReadAll(r)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	delete(secret.Annotations, encryptedAnnotation)
+	if len(secret.Annotations) == 0 {
+		secret.Annotations = nil
+	}
+
+	obj.Object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(&secret)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
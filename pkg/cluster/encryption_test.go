@@ -0,0 +1,160 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+func generateEncryptionKey(t *testing.T) *age.X25519Identity {
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Could not generate key: %s", err)
+	}
+	return key
+}
+
+func TestLoadEncryptionKey(t *testing.T) {
+	key := generateEncryptionKey(t)
+
+	filename := filepath.Join(t.TempDir(), "key.txt")
+	err := os.WriteFile(filename, []byte("# created: today\n"+key.String()+"\n"), 0600)
+	if err != nil {
+		t.Fatalf("Could not write key file: %s", err)
+	}
+
+	loaded, err := LoadEncryptionKey(filename)
+	if err != nil {
+		t.Fatalf("Could not load key file: %s", err)
+	}
+
+	if loaded.String() != key.String() {
+		t.Fatalf("Loaded key does not match the generated one")
+	}
+}
+
+func TestEncryptSecretYaml(t *testing.T) {
+	key := generateEncryptionKey(t)
+
+	secretYaml, _ := k8syaml.Marshal(&corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "org-foo"},
+		Data:       map[string][]byte{"values": []byte("password: hunter2")},
+	})
+
+	encrypted, err := encryptSecretYaml(secretYaml, key)
+	if err != nil {
+		t.Fatalf("Could not encrypt secret: %s", err)
+	}
+
+	if bytes.Contains(encrypted, []byte("hunter2")) {
+		t.Fatalf("Encrypted secret still contains the plain text value")
+	}
+
+	objects, err := decodeManifests(bytes.NewReader(encrypted))
+	if err != nil {
+		t.Fatalf("Could not decode encrypted secret: %s", err)
+	}
+
+	if !isEncryptedSecret(objects[0]) {
+		t.Fatalf("Encrypted secret is not marked as encrypted")
+	}
+
+	err = decryptSecret(objects[0], generateEncryptionKey(t))
+	if !errors.Is(err, invalidEncryptionKey) {
+		t.Fatalf("Decrypting with the wrong key should fail; Is: %v", err)
+	}
+
+	err = decryptSecret(objects[0], key)
+	if err != nil {
+		t.Fatalf("Could not decrypt secret: %s", err)
+	}
+
+	if isEncryptedSecret(objects[0]) {
+		t.Fatalf("Decrypted secret is still marked as encrypted")
+	}
+
+	values, _, _ := unstructured.NestedString(objects[0].Object, "data", "values")
+	if values != "cGFzc3dvcmQ6IGh1bnRlcjI=" {
+		t.Fatalf("Decrypted secret data not correct; Is: %s", values)
+	}
+}
+
+// Test that secrets encrypted by prepare are applied in plain text
+func TestApplyCAPIAppsEncryptedSecrets(t *testing.T) {
+	key := generateEncryptionKey(t)
+
+	c := newTestCluster(prerequisiteObjects(testWcName, testOrgNamespace)...)
+	c.SrcMC.KubernetesClient = newApplyFakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "foobar", Namespace: testWcName},
+		Data:       map[string][]byte{"values": []byte("password: hunter2")},
+	})
+	c.EncryptionKey = key
+	c.Apps = []app.App{newTestApp("loki", "giantswarm", "0.1.0")}
+	c.Apps[0].Spec.UserConfig.Secret.Name = "foobar"
+	c.Apps[0].Spec.UserConfig.Secret.Namespace = testWcName
+
+	filename := writeDumpFile(t, "")
+	path, err := c.AppYamlFile(filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_TRUNC, 0600)
+	if err != nil {
+		t.Fatalf("Could not open dump file: %s", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	err = c.DumpApps(f)
+	if err != nil {
+		t.Fatalf("Could not dump apps: %s", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if bytes.Contains(content, []byte("cGFzc3dvcmQ6IGh1bnRlcjI=")) {
+		t.Fatalf("Dump file contains the plain text secret")
+	}
+
+	c.EncryptionKey = nil
+	_, err = c.ApplyCAPIApps(context.TODO(), filename)
+	if !errors.Is(err, invalidEncryptionKey) {
+		t.Fatalf("Applying an encrypted bundle without key should fail; Is: %v", err)
+	}
+
+	c.EncryptionKey = key
+	_, err = c.ApplyCAPIApps(context.TODO(), filename)
+	if err != nil {
+		t.Fatalf("Apply failed: %s", err)
+	}
+
+	var secret corev1.Secret
+	err = c.DstMC.KubernetesClient.Get(context.TODO(), client.ObjectKey{Name: "cabbage01-foobar", Namespace: testOrgNamespace}, &secret)
+	if err != nil {
+		t.Fatalf("Could not get secret: %s", err)
+	}
+
+	if string(secret.Data["values"]) != "password: hunter2" {
+		t.Fatalf("Applied secret data not correct; Is: %s", secret.Data["values"])
+	}
+
+	if _, ok := secret.Annotations[encryptedAnnotation]; ok {
+		t.Fatalf("Applied secret still carries the encryption annotation")
+	}
+	// verify only reads the apps and works without the key
+	c.EncryptionKey = nil
+	_, err = c.VerifyCAPIApps(context.TODO(), filename)
+	if !errors.Is(err, appsPending) {
+		t.Fatalf("Verifying an encrypted bundle without key should only wait for the apps; Is: %v", err)
+	}
+}
@@ -8,6 +8,14 @@ var clusterNotFound = &microerror.Error{
 	Kind: "clusterNotFound",
 }
 
+var clusterCRDNotInstalled = &microerror.Error{
+	Kind: "clusterCRDNotInstalled",
+}
+
+var clusterNotUnique = &microerror.Error{
+	Kind: "clusterNotUnique",
+}
+
 var clusterNameNotFound = &microerror.Error{
 	Kind: "clusterNameNotFound",
 }
@@ -19,3 +27,71 @@ var clusterUnhealthy = &microerror.Error{
 var MigrationFileEmpty = &microerror.Error{
 	Kind: "migrationFileEmpty",
 }
+
+var dumpFileNotSet = &microerror.Error{
+	Kind: "dumpFileNotSet",
+}
+
+var invalidManifest = &microerror.Error{
+	Kind: "invalidManifest",
+}
+
+var bundleMismatch = &microerror.Error{
+	Kind: "bundleMismatch",
+}
+
+var invalidEncryptionKey = &microerror.Error{
+	Kind: "invalidEncryptionKey",
+}
+
+var appsPending = &microerror.Error{
+	Kind: "appsPending",
+}
+
+var appsFailed = &microerror.Error{
+	Kind: "appsFailed",
+}
+
+var invalidSnapshot = &microerror.Error{
+	Kind: "invalidSnapshot",
+}
+
+var readOnlySnapshot = &microerror.Error{
+	Kind: "readOnlySnapshot",
+}
+
+var contextNotFound = &microerror.Error{
+	Kind: "contextNotFound",
+}
+
+var invalidLoginProvider = &microerror.Error{
+	Kind: "invalidLoginProvider",
+}
+
+var dependencyCycle = &microerror.Error{
+	Kind: "dependencyCycle",
+}
+
+var invalidMetadataRules = &microerror.Error{
+	Kind: "invalidMetadataRules",
+}
+
+var prerequisitesFailed = &microerror.Error{
+	Kind: "prerequisitesFailed",
+}
+
+var invalidUpgradeRules = &microerror.Error{
+	Kind: "invalidUpgradeRules",
+}
+
+var invalidValuesRules = &microerror.Error{
+	Kind: "invalidValuesRules",
+}
+
+var invalidPrerequisite = &microerror.Error{
+	Kind: "invalidPrerequisite",
+}
+
+var prerequisitesTimeout = &microerror.Error{
+	Kind: "prerequisitesTimeout",
+}
@@ -0,0 +1,249 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	gsv1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
+	providerv1alpha1 "github.com/giantswarm/apiextensions/v6/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"golang.org/x/net/context"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	infrastructureKindAWSCluster   = "AWSCluster"
+	infrastructureKindAzureCluster = "AzureCluster"
+	infrastructureKindKVMConfig    = "KVMConfig"
+)
+
+// HealthChecker evaluates the health of a WC from the CRs of one provider.
+type HealthChecker interface {
+	// Health returns the current state of the WC and whether it is healthy.
+	Health(ctx context.Context) (string, bool, error)
+	// String names the provider in messages.
+	String() string
+}
+
+// GetWCHealth returns the state of the WC if it is healthy. The health checker
+// is selected by the infrastructure kind of the CAPI Cluster.
+func (c *ManagementCluster) GetWCHealth(clusterName string) (string, error) {
+	ctx := context.TODO()
+
+	checker, err := c.healthChecker(ctx, clusterName)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	health, healthy, err := checker.Health(ctx)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if !healthy {
+		return "", microerror.Maskf(clusterUnhealthy, "WorkloadCluster not in a healthy condition (%s): %s", checker, health)
+	}
+
+	return health, nil
+}
+
+func (c *ManagementCluster) healthChecker(ctx context.Context, clusterName string) (HealthChecker, error) {
+	capiCluster, err := c.getCluster(ctx, clusterName)
+	if errors.Is(err, clusterCRDNotInstalled) {
+		// vintage KVM MCs have no CAPI CRDs
+		kvmConfig, kvmErr := c.getKVMConfig(ctx, clusterName)
+		if kvmErr != nil {
+			return nil, microerror.Mask(kvmErr)
+		}
+		return &vintageHealthChecker{provider: "kvm", status: kvmConfig.Status.Cluster}, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	ref := capiCluster.Spec.InfrastructureRef
+	if ref == nil || ref.Name == "" {
+		return nil, microerror.Maskf(clusterNameNotFound, "InfrastructureRef not found for %s", clusterName)
+	}
+
+	group := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).Group
+
+	switch {
+	case ref.Kind == infrastructureKindAWSCluster && group == gsv1alpha3.SchemeGroupVersion.Group:
+		awsCluster, err := c.getAwsClusterByName(ctx, ref.Name)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		return &awsHealthChecker{cluster: awsCluster}, nil
+
+	case ref.Kind == infrastructureKindAzureCluster:
+		// CAPZ clusters use the same kind, only vintage clusters have an AzureConfig
+		azureConfig, err := c.getAzureConfig(ctx, clusterName)
+		if errors.Is(err, clusterNotFound) {
+			break
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		return &vintageHealthChecker{provider: "azure", status: azureConfig.Status.Cluster}, nil
+
+	case ref.Kind == infrastructureKindKVMConfig:
+		kvmConfig, err := c.getKVMConfig(ctx, clusterName)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		return &vintageHealthChecker{provider: "kvm", status: kvmConfig.Status.Cluster}, nil
+	}
+
+	return &capiHealthChecker{cluster: capiCluster}, nil
+}
+
+// awsHealthChecker evaluates the first condition of a vintage AWSCluster.
+type awsHealthChecker struct {
+	cluster *gsv1alpha3.AWSCluster
+}
+
+func (h *awsHealthChecker) Health(ctx context.Context) (string, bool, error) {
+	health := getLastAwsCondition(h.cluster.Status.Cluster.Conditions)
+	return health, slices.Contains(validClusterStates, health), nil
+}
+
+func (h *awsHealthChecker) String() string {
+	return "aws"
+}
+
+func getLastAwsCondition(cond []gsv1alpha3.CommonClusterStatusCondition) string {
+	if len(cond) < 1 {
+		return "n/a"
+	}
+
+	return cond[0].Condition
+}
+
+// vintageHealthChecker evaluates the cluster status of a vintage AzureConfig
+// or KVMConfig.
+type vintageHealthChecker struct {
+	provider string
+	status   providerv1alpha1.StatusCluster
+}
+
+func (h *vintageHealthChecker) Health(ctx context.Context) (string, bool, error) {
+	health := getLastVintageCondition(h.status.Conditions)
+	return health, slices.Contains(validClusterStates, health), nil
+}
+
+func (h *vintageHealthChecker) String() string {
+	return h.provider
+}
+
+// getLastVintageCondition returns the newest condition which is true, new
+// conditions are prepended by the operators.
+func getLastVintageCondition(cond []providerv1alpha1.StatusClusterCondition) string {
+	for _, c := range cond {
+		if c.Status == providerv1alpha1.StatusClusterStatusTrue {
+			return c.Type
+		}
+	}
+
+	return "n/a"
+}
+
+// capiHealthChecker evaluates the Ready and ControlPlaneReady conditions of a
+// CAPI Cluster.
+type capiHealthChecker struct {
+	cluster *capi.Cluster
+}
+
+func (h *capiHealthChecker) Health(ctx context.Context) (string, bool, error) {
+	for _, conditionType := range []capi.ConditionType{capi.ReadyCondition, capi.ControlPlaneReadyCondition} {
+		condition := getCapiCondition(h.cluster.Status.Conditions, conditionType)
+		if condition == nil {
+			return fmt.Sprintf("%s n/a", conditionType), false, nil
+		}
+
+		if condition.Status != corev1.ConditionTrue {
+			health := fmt.Sprintf("%s %s", conditionType, condition.Status)
+			if condition.Reason != "" {
+				health = fmt.Sprintf("%s (%s)", health, condition.Reason)
+			}
+			return health, false, nil
+		}
+	}
+
+	return string(capi.ReadyCondition), true, nil
+}
+
+func (h *capiHealthChecker) String() string {
+	return "capi"
+}
+
+func getCapiCondition(conditions capi.Conditions, conditionType capi.ConditionType) *capi.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+
+	return nil
+}
+
+func (c *ManagementCluster) getAwsClusterByName(ctx context.Context, clusterName string) (*gsv1alpha3.AWSCluster, error) {
+	objList := &gsv1alpha3.AWSClusterList{}
+	selector := client.MatchingLabels{capi.ClusterNameLabel: clusterName}
+
+	err := c.KubernetesClient.List(ctx, objList, selector)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if len(objList.Items) == 0 {
+		return nil, microerror.Maskf(clusterNotFound, "Cluster not found for %s", clusterName)
+	}
+
+	if len(objList.Items) > 1 {
+		return nil, microerror.Maskf(clusterNotUnique, "More than one AWSCluster found with name %s", clusterName)
+	}
+
+	return &objList.Items[0], nil
+}
+
+func (c *ManagementCluster) getAzureConfig(ctx context.Context, clusterName string) (*providerv1alpha1.AzureConfig, error) {
+	objList := &providerv1alpha1.AzureConfigList{}
+
+	err := c.KubernetesClient.List(ctx, objList)
+	if meta.IsNoMatchError(err) {
+		return nil, microerror.Maskf(clusterNotFound, "AzureConfig CRD not installed on %s", c.Name)
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for i := range objList.Items {
+		if objList.Items[i].Spec.Cluster.ID == clusterName {
+			return &objList.Items[i], nil
+		}
+	}
+
+	return nil, microerror.Maskf(clusterNotFound, "AzureConfig not found for %s", clusterName)
+}
+
+func (c *ManagementCluster) getKVMConfig(ctx context.Context, clusterName string) (*providerv1alpha1.KVMConfig, error) {
+	objList := &providerv1alpha1.KVMConfigList{}
+
+	err := c.KubernetesClient.List(ctx, objList)
+	if meta.IsNoMatchError(err) {
+		return nil, microerror.Maskf(clusterNotFound, "KVMConfig CRD not installed on %s", c.Name)
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for i := range objList.Items {
+		if objList.Items[i].Spec.Cluster.ID == clusterName {
+			return &objList.Items[i], nil
+		}
+	}
+
+	return nil, microerror.Maskf(clusterNotFound, "KVMConfig not found for %s", clusterName)
+}
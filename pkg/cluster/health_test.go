@@ -0,0 +1,257 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	gsv1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
+	providerv1alpha1 "github.com/giantswarm/apiextensions/v6/pkg/apis/provider/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func healthCapiCluster(apiVersion string, kind string, conditions ...capi.Condition) *capi.Cluster {
+	return &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wc1",
+			Namespace: "org-foo",
+			Labels:    map[string]string{capi.ClusterNameLabel: "wc1"},
+		},
+		Spec: capi.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{APIVersion: apiVersion, Kind: kind, Name: "wc1"},
+		},
+		Status: capi.ClusterStatus{Conditions: conditions},
+	}
+}
+
+func vintageStatus(conditions ...providerv1alpha1.StatusClusterCondition) providerv1alpha1.StatusCluster {
+	return providerv1alpha1.StatusCluster{Conditions: conditions}
+}
+
+func TestGetWCHealth(t *testing.T) {
+	ready := capi.Condition{Type: capi.ReadyCondition, Status: corev1.ConditionTrue}
+	controlPlaneReady := capi.Condition{Type: capi.ControlPlaneReadyCondition, Status: corev1.ConditionTrue}
+	controlPlaneNotReady := capi.Condition{Type: capi.ControlPlaneReadyCondition, Status: corev1.ConditionFalse, Reason: "WaitingForControlPlane"}
+
+	testCases := []struct {
+		name    string
+		objects []runtime.Object
+		health  string
+		err     error
+	}{
+		{
+			name: "capi ready",
+			objects: []runtime.Object{
+				healthCapiCluster("infrastructure.cluster.x-k8s.io/v1beta2", "AWSCluster", ready, controlPlaneReady),
+			},
+			health: "Ready",
+		},
+		{
+			name: "capi control plane not ready",
+			objects: []runtime.Object{
+				healthCapiCluster("infrastructure.cluster.x-k8s.io/v1beta1", "VSphereCluster", ready, controlPlaneNotReady),
+			},
+			err: clusterUnhealthy,
+		},
+		{
+			name: "capi without conditions",
+			objects: []runtime.Object{
+				healthCapiCluster("infrastructure.cluster.x-k8s.io/v1beta1", "VSphereCluster"),
+			},
+			err: clusterUnhealthy,
+		},
+		{
+			name: "vintage aws",
+			objects: []runtime.Object{
+				healthCapiCluster("infrastructure.giantswarm.io/v1alpha3", "AWSCluster"),
+				&gsv1alpha3.AWSCluster{
+					ObjectMeta: metav1.ObjectMeta{Name: "wc1", Namespace: "org-foo", Labels: map[string]string{capi.ClusterNameLabel: "wc1"}},
+					Status: gsv1alpha3.AWSClusterStatus{Cluster: gsv1alpha3.CommonClusterStatus{
+						Conditions: []gsv1alpha3.CommonClusterStatusCondition{{Condition: "Updated"}, {Condition: "Created"}},
+					}},
+				},
+			},
+			health: "Updated",
+		},
+		{
+			name: "vintage azure",
+			objects: []runtime.Object{
+				healthCapiCluster("infrastructure.cluster.x-k8s.io/v1alpha3", "AzureCluster"),
+				&providerv1alpha1.AzureConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: "wc1", Namespace: "default"},
+					Spec:       providerv1alpha1.AzureConfigSpec{Cluster: providerv1alpha1.Cluster{ID: "wc1"}},
+					Status: providerv1alpha1.AzureConfigStatus{Cluster: vintageStatus(
+						providerv1alpha1.StatusClusterCondition{Type: "Updating", Status: "False"},
+						providerv1alpha1.StatusClusterCondition{Type: "Created", Status: "True"},
+					)},
+				},
+			},
+			health: "Created",
+		},
+		{
+			name: "vintage azure creating",
+			objects: []runtime.Object{
+				healthCapiCluster("infrastructure.cluster.x-k8s.io/v1alpha3", "AzureCluster"),
+				&providerv1alpha1.AzureConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: "wc1", Namespace: "default"},
+					Spec:       providerv1alpha1.AzureConfigSpec{Cluster: providerv1alpha1.Cluster{ID: "wc1"}},
+					Status: providerv1alpha1.AzureConfigStatus{Cluster: vintageStatus(
+						providerv1alpha1.StatusClusterCondition{Type: "Creating", Status: "True"},
+					)},
+				},
+			},
+			err: clusterUnhealthy,
+		},
+		{
+			name: "capz without azureconfig",
+			objects: []runtime.Object{
+				healthCapiCluster("infrastructure.cluster.x-k8s.io/v1beta1", "AzureCluster", ready, controlPlaneReady),
+			},
+			health: "Ready",
+		},
+		{
+			name: "no cluster",
+			err:  clusterNotFound,
+		},
+		{
+			name: "more than one cluster",
+			objects: []runtime.Object{
+				healthCapiCluster("infrastructure.cluster.x-k8s.io/v1beta1", "VSphereCluster", ready, controlPlaneReady),
+				func() *capi.Cluster {
+					cluster := healthCapiCluster("infrastructure.cluster.x-k8s.io/v1beta1", "VSphereCluster", ready, controlPlaneReady)
+					cluster.Namespace = "org-bar"
+					return cluster
+				}(),
+				&providerv1alpha1.KVMConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: "wc1", Namespace: "default"},
+					Spec:       providerv1alpha1.KVMConfigSpec{Cluster: providerv1alpha1.Cluster{ID: "wc1"}},
+				},
+			},
+			err: clusterNotUnique,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mc := &ManagementCluster{
+				Name:             "gauss",
+				KubernetesClient: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(tc.objects...).Build(),
+			}
+
+			health, err := mc.GetWCHealth("wc1")
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("Error is wrong. Is: %v; Want: %s", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if health != tc.health {
+				t.Fatalf("Health is wrong. Is: %s; Want: %s", health, tc.health)
+			}
+		})
+	}
+}
+
+// Test that a mistyped WC name on a CAPI MC reports the missing Cluster
+// instead of falling back to the vintage KVM checker
+func TestGetWCHealthMissingOnCAPI(t *testing.T) {
+	mc := &ManagementCluster{
+		Name: "gauss",
+		KubernetesClient: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+			healthCapiCluster("infrastructure.cluster.x-k8s.io/v1beta2", "AWSCluster"),
+		).Build(),
+	}
+
+	_, err := mc.GetWCHealth("wc2")
+	if !errors.Is(err, clusterNotFound) {
+		t.Fatalf("Error is wrong. Is: %v; Want: %s", err, clusterNotFound)
+	}
+
+	want := "Cluster not found for gauss-wc2"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("Error message is wrong. Is: %s; Want: %s", err, want)
+	}
+}
+
+func TestGetWCHealthWithoutCAPI(t *testing.T) {
+	kvmConfig := &providerv1alpha1.KVMConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "wc1", Namespace: "default"},
+		Spec:       providerv1alpha1.KVMConfigSpec{Cluster: providerv1alpha1.Cluster{ID: "wc1"}},
+		Status: providerv1alpha1.KVMConfigStatus{Cluster: vintageStatus(
+			providerv1alpha1.StatusClusterCondition{Type: "Updated", Status: "True"},
+		)},
+	}
+
+	// the scheme of a vintage KVM MC lacks the CAPI types
+	kvmScheme := runtime.NewScheme()
+	_ = providerv1alpha1.AddToScheme(kvmScheme)
+
+	testCases := []struct {
+		name    string
+		builder *fake.ClientBuilder
+		objects []client.Object
+		health  string
+		err     error
+	}{
+		{
+			name:    "capi types not registered",
+			builder: fake.NewClientBuilder().WithScheme(kvmScheme),
+			objects: []client.Object{kvmConfig},
+			health:  "Updated",
+		},
+		{
+			name: "capi crd not installed",
+			builder: fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if _, ok := list.(*capi.ClusterList); ok {
+						return &meta.NoKindMatchError{GroupKind: capi.GroupVersion.WithKind("Cluster").GroupKind()}
+					}
+					return c.List(ctx, list, opts...)
+				},
+			}),
+			objects: []client.Object{kvmConfig},
+			health:  "Updated",
+		},
+		{
+			name:    "kvmconfig missing",
+			builder: fake.NewClientBuilder().WithScheme(kvmScheme),
+			err:     clusterNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mc := &ManagementCluster{
+				Name:             "gauss",
+				KubernetesClient: tc.builder.WithObjects(tc.objects...).Build(),
+			}
+
+			health, err := mc.GetWCHealth("wc1")
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("Error is wrong. Is: %v; Want: %s", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if health != tc.health {
+				t.Fatalf("Health is wrong. Is: %s; Want: %s", health, tc.health)
+			}
+		})
+	}
+}
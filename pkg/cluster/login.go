@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	LoginProviderOpsctl     = "opsctl"
+	LoginProviderKubectlGs  = "kubectl-gs"
+	LoginProviderKubeconfig = "kubeconfig"
+	LoginProviderExec       = "exec"
+)
+
+// LoginProviders lists all login providers which can be selected by name.
+var LoginProviders = []string{
+	LoginProviderOpsctl,
+	LoginProviderKubectlGs,
+	LoginProviderKubeconfig,
+	LoginProviderExec,
+}
+
+// LoginProvider creates the kubeconfig context of an MC when it is missing.
+type LoginProvider interface {
+	// Login writes contextName for the MC to kubeconfigFile. The output of
+	// the login is written to out.
+	Login(out io.Writer, mc string, kubeconfigFile string, contextName string) error
+	// String names the provider in messages.
+	String() string
+}
+
+// NewLoginProvider returns the login provider of the given name. command is
+// only used by the exec provider.
+func NewLoginProvider(name string, command string) (LoginProvider, error) {
+	switch name {
+	case LoginProviderOpsctl:
+		return OpsctlLoginProvider{}, nil
+	case LoginProviderKubectlGs:
+		return KubectlGsLoginProvider{}, nil
+	case LoginProviderKubeconfig:
+		return KubeconfigLoginProvider{}, nil
+	case LoginProviderExec:
+		if strings.TrimSpace(command) == "" {
+			return nil, microerror.Maskf(invalidLoginProvider, "login provider %q needs a command", name)
+		}
+		return ExecLoginProvider{Command: command}, nil
+	}
+
+	return nil, microerror.Maskf(invalidLoginProvider, "login provider must be one of %s", strings.Join(LoginProviders, ", "))
+}
+
+// OpsctlLoginProvider runs `opsctl login`, which creates the gs-<mc> context.
+type OpsctlLoginProvider struct{}
+
+func (p OpsctlLoginProvider) Login(out io.Writer, mc string, kubeconfigFile string, contextName string) error {
+	_, _ = fmt.Fprintf(out, "Context %s not found, executing 'opsctl login', check your browser window.\n", contextName)
+	return runLoginCommand(out, kubeconfigFile, "opsctl", "login", "--no-cache", mc)
+}
+
+func (p OpsctlLoginProvider) String() string {
+	return LoginProviderOpsctl
+}
+
+// KubectlGsLoginProvider runs `kubectl gs login`, which creates the gs-<mc>
+// context.
+type KubectlGsLoginProvider struct{}
+
+func (p KubectlGsLoginProvider) Login(out io.Writer, mc string, kubeconfigFile string, contextName string) error {
+	_, _ = fmt.Fprintf(out, "Context %s not found, executing 'kubectl gs login', check your browser window.\n", contextName)
+	return runLoginCommand(out, kubeconfigFile, "kubectl", "gs", "login", mc)
+}
+
+func (p KubectlGsLoginProvider) String() string {
+	return LoginProviderKubectlGs
+}
+
+// KubeconfigLoginProvider never logs in, the context must exist already.
+type KubeconfigLoginProvider struct{}
+
+func (p KubeconfigLoginProvider) Login(out io.Writer, mc string, kubeconfigFile string, contextName string) error {
+	return nil
+}
+
+func (p KubeconfigLoginProvider) String() string {
+	return LoginProviderKubeconfig
+}
+
+// ExecLoginProvider runs a custom command. The placeholders {mc}, {context}
+// and {kubeconfig} in the command are replaced, KUBECONFIG is set to the
+// kubeconfig file.
+type ExecLoginProvider struct {
+	Command string
+}
+
+func (p ExecLoginProvider) Login(out io.Writer, mc string, kubeconfigFile string, contextName string) error {
+	replacer := strings.NewReplacer("{mc}", mc, "{context}", contextName, "{kubeconfig}", kubeconfigFile)
+
+	args := strings.Fields(p.Command)
+	for i := range args {
+		args[i] = replacer.Replace(args[i])
+	}
+
+	_, _ = fmt.Fprintf(out, "Context %s not found, executing '%s'.\n", contextName, strings.Join(args, " "))
+	return runLoginCommand(out, kubeconfigFile, args[0], args[1:]...)
+}
+
+func (p ExecLoginProvider) String() string {
+	return fmt.Sprintf("%s (%s)", LoginProviderExec, p.Command)
+}
+
+func runLoginCommand(out io.Writer, kubeconfigFile string, name string, args ...string) error {
+	c := exec.Command(name, args...) //nolint:gosec
+
+	c.Env = append(os.Environ(), fmt.Sprintf("KUBECONFIG=%s", kubeconfigFile))
+	c.Stdout = out
+	c.Stderr = os.Stderr
+	c.Stdin = os.Stdin
+
+	err := c.Run()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	return nil
+}
+
+// kubeconfigPath returns the kubeconfig file to use, falling back to
+// $KUBECONFIG and ~/.kube/config.
+func kubeconfigPath(kubeconfigFile string) (string, error) {
+	if kubeconfigFile == "" {
+		kubeconfigFile = os.Getenv("KUBECONFIG")
+	}
+	if kubeconfigFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+		kubeconfigFile = fmt.Sprintf("%s/.kube/config", home)
+	}
+
+	return kubeconfigFile, nil
+}
+
+// contextExists checks the kubeconfig file for the context. A missing file
+// has no contexts.
+func contextExists(kubeconfigFile string, contextName string) (bool, error) {
+	config, err := clientcmd.LoadFromFile(kubeconfigFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	_, found := config.Contexts[contextName]
+	return found, nil
+}
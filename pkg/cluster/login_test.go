@@ -0,0 +1,107 @@
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: other
+  cluster:
+    server: https://127.0.0.1:1
+contexts:
+- name: %s
+  context:
+    cluster: other
+    user: other
+users:
+- name: other
+  user: {}
+`
+
+func TestNewLoginProvider(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+		want    string
+		wantErr bool
+	}{
+		{name: LoginProviderOpsctl, want: "opsctl"},
+		{name: LoginProviderKubectlGs, want: "kubectl-gs"},
+		{name: LoginProviderKubeconfig, want: "kubeconfig"},
+		{name: LoginProviderExec, command: "my-login {mc}", want: "exec (my-login {mc})"},
+		{name: LoginProviderExec, wantErr: true},
+		{name: "teleport", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		provider, err := NewLoginProvider(tc.name, tc.command)
+		if tc.wantErr {
+			if !errors.Is(err, invalidLoginProvider) {
+				t.Fatalf("Login provider %q should be rejected. Is: %v", tc.name, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if provider.String() != tc.want {
+			t.Fatalf("Login provider is wrong. Is: %s; Want: %s", provider, tc.want)
+		}
+	}
+}
+
+func TestLoginMCMissingContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	err := os.WriteFile(kubeconfig, []byte(fmt.Sprintf(testKubeconfig, "other")), 0600)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	_, err = LoginMC(ConnectionConfig{Name: "gauss", Kubeconfig: kubeconfig, LoginProvider: KubeconfigLoginProvider{}})
+	if !errors.Is(err, contextNotFound) {
+		t.Fatalf("Missing context should be reported. Is: %v", err)
+	}
+}
+
+func TestExecLoginProvider(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+
+	script := filepath.Join(dir, "login.sh")
+	err := os.WriteFile(script, []byte(fmt.Sprintf("#!/bin/sh\necho \"logged in to $2\"\ncat > \"$KUBECONFIG\" <<EOF\n%sEOF\n", fmt.Sprintf(testKubeconfig, "$1-$2"))), 0700) //nolint:gosec
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	exists, err := contextExists(kubeconfig, "gs-gauss")
+	if err != nil || exists {
+		t.Fatalf("Context should not exist in a missing kubeconfig. Is: %v %v", exists, err)
+	}
+
+	provider := ExecLoginProvider{Command: script + " {context} {mc}"}
+	var out bytes.Buffer
+	err = provider.Login(&out, "gauss", kubeconfig, "gs-gauss")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// the output of the login command goes to the given writer, not stdout
+	if !strings.Contains(out.String(), "logged in to gauss") {
+		t.Fatalf("Login output is wrong. Is: %q", out.String())
+	}
+
+	exists, err = contextExists(kubeconfig, "gs-gauss-gauss")
+	if err != nil || !exists {
+		t.Fatalf("Context should be written by the login command. Is: %v %v", exists, err)
+	}
+}
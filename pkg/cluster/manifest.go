@@ -0,0 +1,42 @@
+package cluster
+
+import (
+	"errors"
+	"io"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// decodeManifests reads a multi-document yaml stream as written by DumpApps
+// and returns every non-empty document as an unstructured object.
+func decodeManifests(r io.Reader) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var doc map[string]interface{}
+
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		// skip empty documents, eg. a trailing "---"
+		if len(doc) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: doc}
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+			return nil, microerror.Maskf(invalidManifest, "Document without apiVersion/kind found: %s/%s", obj.GetNamespace(), obj.GetName())
+		}
+
+		objects = append(objects, obj)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,249 @@
+package cluster
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/giantswarm/microerror"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
+)
+
+const (
+	MetadataActionDrop   = "drop"
+	MetadataActionRename = "rename"
+	MetadataActionSet    = "set"
+)
+
+// MetadataRules rewrite the labels and annotations copied from the source
+// objects to the migrated apps, config maps and secrets. All rules are
+// applied in order.
+//
+//	rules:
+//	- action: drop
+//	  annotations: ["kubectl.kubernetes.io/last-applied-configuration"]
+//	- action: rename
+//	  labels: ["team"]
+//	  to: application.giantswarm.io/team
+//	- action: set
+//	  kinds: ["App"]
+//	  labels: ["giantswarm.io/migrated-from"]
+//	  value: "{{ .SourceWcName }}"
+type MetadataRules struct {
+	Rules []MetadataRule `json:"rules"`
+}
+
+// MetadataRule applies an action to the label and annotation keys it lists.
+type MetadataRule struct {
+	Action string `json:"action"`
+
+	// Kinds limits the rule to objects of these kinds, eg. App, ConfigMap or
+	// Secret. The rule applies to all kinds if empty.
+	Kinds []string `json:"kinds,omitempty"`
+	// Labels and Annotations are the keys the rule applies to. Keys of drop
+	// rules may contain `*` wildcards.
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+
+	// To is the new key of rename rules, the value is kept.
+	To string `json:"to,omitempty"`
+	// Value is set by set rules. It is a template which may use .WcName,
+	// .SourceWcName, .OrgNamespace, .Organization and, for existing keys,
+	// .Value.
+	Value string `json:"value,omitempty"`
+}
+
+// metadataTemplateData is passed to the value templates of set rules.
+type metadataTemplateData struct {
+	WcName       string
+	SourceWcName string
+	OrgNamespace string
+	Organization string
+	Value        string
+}
+
+// DefaultMetadataRules returns the rules used if no rules file is given. They
+// drop keys which are managed by the server or only make sense on the source
+// MC.
+func DefaultMetadataRules() *MetadataRules {
+	return &MetadataRules{
+		Rules: []MetadataRule{
+			{
+				Action:      MetadataActionDrop,
+				Annotations: []string{"kubectl.kubernetes.io/last-applied-configuration"},
+			},
+			{
+				// the helm release owning the source object does not exist
+				// on the destination MC
+				Action:      MetadataActionDrop,
+				Annotations: []string{"meta.helm.sh/*"},
+			},
+			{
+				Action: MetadataActionDrop,
+				Labels: []string{
+					"release.giantswarm.io/version",
+					createdLabel,
+				},
+			},
+			{
+				// the app-operator of the source MC reconciles the app with
+				// this label, NewAppCR sets the value used on CAPI
+				Action: MetadataActionDrop,
+				Labels: []string{"app-operator.giantswarm.io/version"},
+			},
+		},
+	}
+}
+
+// LoadMetadataRules reads and validates a rules file. The built-in default
+// rules are not added, they can be copied into the file if needed.
+func LoadMetadataRules(filename string) (*MetadataRules, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var rules MetadataRules
+	err = k8syaml.UnmarshalStrict(data, &rules)
+	if err != nil {
+		return nil, microerror.Maskf(invalidMetadataRules, "Could not parse metadata rules %s: %s", filename, err)
+	}
+
+	err = rules.Validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &rules, nil
+}
+
+func (r *MetadataRules) Validate() error {
+	for i, rule := range r.Rules {
+		if len(rule.Labels) == 0 && len(rule.Annotations) == 0 {
+			return microerror.Maskf(invalidMetadataRules, "rules[%d] must list labels or annotations", i)
+		}
+
+		switch rule.Action {
+		case MetadataActionDrop:
+		case MetadataActionRename:
+			if rule.To == "" {
+				return microerror.Maskf(invalidMetadataRules, "rules[%d].to must not be empty", i)
+			}
+			if len(rule.Labels)+len(rule.Annotations) > 1 {
+				return microerror.Maskf(invalidMetadataRules, "rules[%d] must rename a single key", i)
+			}
+		case MetadataActionSet:
+			_, err := template.New("value").Option("missingkey=error").Parse(rule.Value)
+			if err != nil {
+				return microerror.Maskf(invalidMetadataRules, "rules[%d].value is invalid: %s", i, err)
+			}
+		default:
+			return microerror.Maskf(invalidMetadataRules, "rules[%d].action must be %q, %q or %q", i, MetadataActionDrop, MetadataActionRename, MetadataActionSet)
+		}
+
+		if rule.Action != MetadataActionDrop && strings.Contains(strings.Join(append(rule.Labels, rule.Annotations...), ""), "*") {
+			return microerror.Maskf(invalidMetadataRules, "rules[%d] may only use wildcards to drop keys", i)
+		}
+	}
+
+	return nil
+}
+
+// migrateMetadata returns copies of the labels and annotations of a source
+// object rewritten for the destination MC. The cluster label and dependencies
+// are pointed to the destination MC before the rules are applied.
+func (c *Cluster) migrateMetadata(kind string, labels map[string]string, annotations map[string]string) (map[string]string, map[string]string, error) {
+	rules := c.MetadataRules
+	if rules == nil {
+		rules = DefaultMetadataRules()
+	}
+
+	data := metadataTemplateData{
+		WcName:       c.DestinationWcName(),
+		SourceWcName: c.WcName,
+		OrgNamespace: c.OrgNamespace,
+		Organization: organizationFromNamespace(c.OrgNamespace),
+	}
+
+	newLabels := c.clusterLabels(labels)
+	newAnnotations := c.migrateAnnotations(annotations)
+
+	var err error
+	for _, rule := range rules.Rules {
+		if len(rule.Kinds) > 0 && !apps.MatchesAny(rule.Kinds, kind) {
+			continue
+		}
+
+		newLabels, err = rule.apply(newLabels, rule.Labels, data)
+		if err != nil {
+			return nil, nil, microerror.Mask(err)
+		}
+
+		newAnnotations, err = rule.apply(newAnnotations, rule.Annotations, data)
+		if err != nil {
+			return nil, nil, microerror.Mask(err)
+		}
+	}
+
+	return newLabels, newAnnotations, nil
+}
+
+// apply rewrites the given keys of the metadata in place. metadata is created
+// if a set rule adds the first key.
+func (r *MetadataRule) apply(metadata map[string]string, keys []string, data metadataTemplateData) (map[string]string, error) {
+	for _, key := range keys {
+		switch r.Action {
+		case MetadataActionDrop:
+			for existing := range metadata {
+				if apps.GlobMatch(key, existing) {
+					delete(metadata, existing)
+				}
+			}
+
+		case MetadataActionRename:
+			value, found := metadata[key]
+			if !found {
+				continue
+			}
+			delete(metadata, key)
+			metadata[r.To] = value
+
+		case MetadataActionSet:
+			data.Value = metadata[key]
+
+			tmpl, err := template.New("value").Option("missingkey=error").Parse(r.Value)
+			if err != nil {
+				return nil, microerror.Maskf(invalidMetadataRules, "Could not parse value of %s: %s", key, err)
+			}
+
+			var value bytes.Buffer
+			err = tmpl.Execute(&value, data)
+			if err != nil {
+				return nil, microerror.Maskf(invalidMetadataRules, "Could not render value of %s: %s", key, err)
+			}
+
+			if metadata == nil {
+				metadata = map[string]string{}
+			}
+			metadata[key] = value.String()
+		}
+	}
+
+	return metadata, nil
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+
+	newMetadata := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		newMetadata[key] = value
+	}
+
+	return newMetadata
+}
@@ -0,0 +1,174 @@
+package cluster
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestMigrateMetadataDefaultRules(t *testing.T) {
+	c := Cluster{
+		WcName:       "wc1",
+		OrgNamespace: "org-foo",
+	}
+
+	labels, annotations, err := c.migrateMetadata("App", map[string]string{
+		"giantswarm.io/cluster":              "wc1",
+		"release.giantswarm.io/version":      "19.0.0",
+		"app-operator.giantswarm.io/version": "6.4.0",
+		"team":                               "honeybadger",
+	}, map[string]string{
+		"kubectl.kubernetes.io/last-applied-configuration": "{}",
+		"meta.helm.sh/release-name":                        "loki",
+		"meta.helm.sh/release-namespace":                   "wc1",
+		"foo.io/keep":                                      "true",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(labels) != 2 || labels["giantswarm.io/cluster"] != "wc1" || labels["team"] != "honeybadger" {
+		t.Fatalf("Labels not migrated; Is: %v", labels)
+	}
+	if len(annotations) != 1 || annotations["foo.io/keep"] != "true" {
+		t.Fatalf("Annotations not migrated; Is: %v", annotations)
+	}
+}
+
+func TestMigrateAppOperatorVersion(t *testing.T) {
+	newApp := func(name string, inCluster bool) app.App {
+		return app.App{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "wc1",
+				Labels:    map[string]string{"app-operator.giantswarm.io/version": "6.4.0"},
+			},
+			Spec: app.AppSpec{
+				Name:       name,
+				Namespace:  name,
+				Version:    "0.1.0",
+				Catalog:    "giantswarm",
+				KubeConfig: app.AppSpecKubeConfig{InCluster: inCluster},
+			},
+		}
+	}
+
+	c := Cluster{
+		WcName:       "wc1",
+		OrgNamespace: "org-foo",
+		SrcMC:        &ManagementCluster{Name: "gauss"},
+		Apps:         []app.App{newApp("loki", false), newApp("service-mesh", true)},
+	}
+
+	yamlText, err := c.migrateApps()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// NewAppCR sets 0.0.0 for in-cluster apps and no version for apps of the WC
+	want := map[string]string{"wc1-loki": "", "wc1-service-mesh": "0.0.0"}
+	for _, obj := range yamlText {
+		var migratedApp app.App
+		err := yaml.Unmarshal(obj, &migratedApp)
+		if err != nil {
+			t.Fatalf("Could not unmarshal yaml: %s", err)
+		}
+
+		version := migratedApp.Labels["app-operator.giantswarm.io/version"]
+		if version != want[migratedApp.Name] {
+			t.Fatalf("App operator version of %s not correct; Is: %q; Want: %q", migratedApp.Name, version, want[migratedApp.Name])
+		}
+	}
+}
+
+func TestMigrateMetadataRules(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "metadata-rules.yaml")
+	err := os.WriteFile(filename, []byte(`rules:
+- action: rename
+  labels: ["team"]
+  to: application.giantswarm.io/team
+- action: set
+  kinds: ["App"]
+  labels: ["giantswarm.io/migrated-from"]
+  value: "{{ .SourceWcName }}"
+- action: set
+  annotations: ["foo.io/owner"]
+  value: "{{ .Organization }}-{{ .Value }}"
+- action: drop
+  annotations: ["kubectl.kubernetes.io/*"]
+`), 0600)
+	if err != nil {
+		t.Fatalf("Could not write rules: %s", err)
+	}
+
+	rules, err := LoadMetadataRules(filename)
+	if err != nil {
+		t.Fatalf("Could not load rules: %s", err)
+	}
+
+	c := Cluster{
+		WcName:        "wc1",
+		DstWcName:     "wc2",
+		OrgNamespace:  "org-foo",
+		MetadataRules: rules,
+	}
+
+	sourceLabels := map[string]string{"giantswarm.io/cluster": "wc1", "team": "honeybadger"}
+	sourceAnnotations := map[string]string{
+		"foo.io/owner": "bar",
+		"kubectl.kubernetes.io/last-applied-configuration": "{}",
+	}
+
+	labels, annotations, err := c.migrateMetadata("App", sourceLabels, sourceAnnotations)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if labels["giantswarm.io/cluster"] != "wc2" {
+		t.Fatalf("Cluster label not migrated; Is: %s; Want: %s", labels["giantswarm.io/cluster"], "wc2")
+	}
+	if _, found := labels["team"]; found || labels["application.giantswarm.io/team"] != "honeybadger" {
+		t.Fatalf("Label not renamed; Is: %v", labels)
+	}
+	if labels["giantswarm.io/migrated-from"] != "wc1" {
+		t.Fatalf("Label not set; Is: %s; Want: %s", labels["giantswarm.io/migrated-from"], "wc1")
+	}
+	if len(annotations) != 1 || annotations["foo.io/owner"] != "foo-bar" {
+		t.Fatalf("Annotations not migrated; Is: %v", annotations)
+	}
+
+	if sourceLabels["team"] != "honeybadger" || sourceLabels["giantswarm.io/cluster"] != "wc1" {
+		t.Fatalf("Labels of the source object changed; Is: %v", sourceLabels)
+	}
+
+	labels, _, err = c.migrateMetadata("ConfigMap", nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, found := labels["giantswarm.io/migrated-from"]; found {
+		t.Fatalf("Rule limited to apps applied to config map; Is: %v", labels)
+	}
+}
+
+func TestMetadataRulesValidate(t *testing.T) {
+	for name, rule := range map[string]MetadataRule{
+		"missing keys":      {Action: MetadataActionDrop},
+		"invalid action":    {Action: "copy", Labels: []string{"foo"}},
+		"rename without to": {Action: MetadataActionRename, Labels: []string{"foo"}},
+		"rename two keys":   {Action: MetadataActionRename, Labels: []string{"foo"}, Annotations: []string{"bar"}, To: "baz"},
+		"rename wildcard":   {Action: MetadataActionRename, Labels: []string{"foo/*"}, To: "baz"},
+		"invalid template":  {Action: MetadataActionSet, Labels: []string{"foo"}, Value: "{{ .WcName"},
+	} {
+		rules := MetadataRules{Rules: []MetadataRule{rule}}
+
+		err := rules.Validate()
+		if !errors.Is(err, invalidMetadataRules) {
+			t.Fatalf("Rule with %s should be invalid; Is: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
+)
+
+// Mode selects where the apps of a WC are found on the source MC and how they
+// are transformed for the destination MC.
+type Mode string
+
+const (
+	// ModeVintage migrates the apps of the WC namespace of a vintage MC. Apps
+	// and their config are prefixed with the WC name.
+	ModeVintage Mode = "vintage"
+	// ModeCAPI migrates the apps of the WC from the org namespace of a CAPI
+	// MC. Names are preserved, only references to the WC are remapped.
+	ModeCAPI Mode = "capi"
+)
+
+// Modes lists all modes which can be selected by name.
+var Modes = []string{string(ModeVintage), string(ModeCAPI)}
+
+// IsCAPI tells whether the WC is migrated from a CAPI MC.
+func (c *Cluster) IsCAPI() bool {
+	return c.Mode == ModeCAPI
+}
+
+// DestinationWcName returns the name of the WC on the destination MC.
+func (c *Cluster) DestinationWcName() string {
+	if c.DstWcName != "" {
+		return c.DstWcName
+	}
+
+	return c.WcName
+}
+
+// GetAppCRs returns the apps of the WC on the source MC which should be
+// migrated and the ones skipped by the rules.
+func (c *Cluster) GetAppCRs(rules *apps.Rules) ([]app.App, []apps.SkippedApp, error) {
+	var migratedApps []app.App
+	var skippedApps []apps.SkippedApp
+	var err error
+
+	if c.IsCAPI() {
+		migratedApps, skippedApps, err = apps.GetCAPIAppCRs(c.SrcMC.KubernetesClient, c.WcName, c.OrgNamespace, rules)
+	} else {
+		migratedApps, skippedApps, err = apps.GetAppCRs(c.SrcMC.KubernetesClient, c.WcName, rules)
+	}
+	if err != nil {
+		return migratedApps, skippedApps, microerror.Mask(err)
+	}
+
+	return migratedApps, skippedApps, nil
+}
+
+// appName returns the name of the migrated app. Vintage apps are prefixed
+// with the WC name, apps of CAPI clusters keep their name. A WC name prefix is
+// replaced with the name of the WC on the destination MC.
+func (c *Cluster) appName(name string) string {
+	if c.IsCAPI() {
+		if strings.HasPrefix(name, c.WcName+"-") {
+			return c.prefixedName(name)
+		}
+		return name
+	}
+
+	return c.prefixedName(name)
+}
+
+// configObjectName returns the name of a migrated config map or secret, it is
+// named like the apps.
+func (c *Cluster) configObjectName(name string) string {
+	return c.appName(name)
+}
+
+func (c *Cluster) prefixedName(name string) string {
+	// make sure we trim the clustername if it somehow was prefixed on the app
+	name = strings.TrimPrefix(name, c.WcName)
+	// in case we trimmed the clustername, we might need to trim the trailing dash
+	// now as well.
+	name = strings.TrimPrefix(name, "-")
+	// now prefix the app with the name of the WC on the destination MC
+	return fmt.Sprintf("%s-%s", c.DestinationWcName(), name)
+}
+
+// clusterReference returns the name of a config map or secret on the
+// destination MC which is created with the WC there, like its cluster values
+// or kubeconfig. found is false for any other name.
+func (c *Cluster) clusterReference(name string) (string, bool) {
+	switch name {
+	case apps.ClusterValuesName(c.WcName):
+		return apps.ClusterValuesName(c.DestinationWcName()), true
+	case apps.KubeconfigName(c.WcName):
+		return apps.KubeconfigName(c.DestinationWcName()), true
+	}
+
+	return "", false
+}
+
+// clusterLabels returns a copy of the labels with the cluster label pointing
+// to the WC on the destination MC.
+func (c *Cluster) clusterLabels(labels map[string]string) map[string]string {
+	newLabels := copyMetadata(labels)
+
+	if newLabels != nil && newLabels[apps.ClusterLabel] == c.WcName {
+		newLabels[apps.ClusterLabel] = c.DestinationWcName()
+	}
+
+	return newLabels
+}
@@ -0,0 +1,272 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CheckResult is the outcome of a single prerequisite check.
+type CheckResult string
+
+const (
+	CheckResultPass CheckResult = "pass"
+	CheckResultWarn CheckResult = "warn"
+	CheckResultFail CheckResult = "fail"
+)
+
+// catalogNamespaces are searched by app-operator for catalogs of apps
+// without spec.catalogNamespace
+var catalogNamespaces = []string{
+	"default",
+	"giantswarm",
+}
+
+var organizationGVK = schema.GroupVersionKind{
+	Group:   "security.giantswarm.io",
+	Version: "v1alpha1",
+	Kind:    "Organization",
+}
+
+// PrerequisiteCheck is a single check of the destination MC.
+type PrerequisiteCheck struct {
+	Name    string      `json:"name"`
+	Result  CheckResult `json:"result"`
+	Message string      `json:"message"`
+}
+
+// CheckDestination checks if the destination MC is ready for the apps in
+// c.Apps. The apps are migrated in memory to check the objects which would be
+// applied: the org namespace, the Organization CR and the catalogs used by the
+// apps must exist, the catalogs must index the apps and the migrated apps and
+// their config should not exist yet.
+// All checks are returned, the error reports if any of them failed.
+func (c *Cluster) CheckDestination() ([]PrerequisiteCheck, error) {
+	yaml, err := c.migrateApps()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, obj := range yaml {
+		decoded, err := decodeManifests(bytes.NewReader(obj))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		objects = append(objects, decoded...)
+	}
+
+	k8sClient := c.DstMC.KubernetesClient
+
+	var checks []PrerequisiteCheck
+
+	check, err := checkNamespace(k8sClient, c.OrgNamespace)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	checks = append(checks, check)
+
+	check, err = checkOrganization(k8sClient, organizationFromNamespace(c.OrgNamespace))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	checks = append(checks, check)
+
+	catalogChecks, err := checkCatalogs(k8sClient, objects)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	checks = append(checks, catalogChecks...)
+
+	versionChecks, err := c.CheckAppVersions()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	checks = append(checks, versionChecks...)
+
+	objectChecks, err := checkObjectsAbsent(k8sClient, objects)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	checks = append(checks, objectChecks...)
+
+	var failed int
+	for _, check := range checks {
+		if check.Result == CheckResultFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return checks, microerror.Maskf(prerequisitesFailed, "%d checks of the destination MC %s failed", failed, c.DstMC.Name)
+	}
+
+	return checks, nil
+}
+
+func checkNamespace(k8sClient client.Client, name string) (PrerequisiteCheck, error) {
+	check := PrerequisiteCheck{
+		Name:    "namespace",
+		Result:  CheckResultPass,
+		Message: fmt.Sprintf("Namespace %s exists", name),
+	}
+
+	var namespace corev1.Namespace
+	err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: name}, &namespace)
+	if errors.IsNotFound(err) {
+		check.Result = CheckResultFail
+		check.Message = fmt.Sprintf("Namespace %s not found", name)
+	} else if err != nil {
+		return PrerequisiteCheck{}, microerror.Mask(err)
+	}
+
+	return check, nil
+}
+
+func checkOrganization(k8sClient client.Client, name string) (PrerequisiteCheck, error) {
+	check := PrerequisiteCheck{
+		Name:    "organization",
+		Result:  CheckResultPass,
+		Message: fmt.Sprintf("Organization %s exists", name),
+	}
+
+	organization := &unstructured.Unstructured{}
+	organization.SetGroupVersionKind(organizationGVK)
+
+	err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: name}, organization)
+	if meta.IsNoMatchError(err) {
+		check.Result = CheckResultWarn
+		check.Message = fmt.Sprintf("Organization %s not checked, the Organization CRD is not installed", name)
+	} else if errors.IsNotFound(err) {
+		check.Result = CheckResultFail
+		check.Message = fmt.Sprintf("Organization %s not found", name)
+	} else if err != nil {
+		return PrerequisiteCheck{}, microerror.Mask(err)
+	}
+
+	return check, nil
+}
+
+// checkCatalogs checks every catalog referenced by the migrated apps once.
+func checkCatalogs(k8sClient client.Client, objects []*unstructured.Unstructured) ([]PrerequisiteCheck, error) {
+	var checks []PrerequisiteCheck
+
+	checked := map[string]bool{}
+	for _, obj := range objects {
+		if obj.GetKind() != "App" {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(obj.Object, "spec", "catalog")
+		namespace, _, _ := unstructured.NestedString(obj.Object, "spec", "catalogNamespace")
+
+		key := namespace + "/" + name
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		namespaces := catalogNamespaces
+		if namespace != "" {
+			namespaces = []string{namespace}
+		}
+
+		check, err := checkCatalog(k8sClient, name, namespaces)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+func checkCatalog(k8sClient client.Client, name string, namespaces []string) (PrerequisiteCheck, error) {
+	for _, namespace := range namespaces {
+		var catalog app.Catalog
+		err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: namespace}, &catalog)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return PrerequisiteCheck{}, microerror.Mask(err)
+		}
+
+		return PrerequisiteCheck{
+			Name:    "catalog",
+			Result:  CheckResultPass,
+			Message: fmt.Sprintf("Catalog %s/%s exists", namespace, name),
+		}, nil
+	}
+
+	return PrerequisiteCheck{
+		Name:    "catalog",
+		Result:  CheckResultFail,
+		Message: fmt.Sprintf("Catalog %s not found in namespaces %v", name, namespaces),
+	}, nil
+}
+
+// checkObjectsAbsent reports every migrated object which already exists. Objects
+// created by an earlier apply are only a warning, apply updates them. Other
+// objects would be overwritten.
+func checkObjectsAbsent(k8sClient client.Client, objects []*unstructured.Unstructured) ([]PrerequisiteCheck, error) {
+	var checks []PrerequisiteCheck
+
+	for _, obj := range objects {
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+
+		err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), existing)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		check := PrerequisiteCheck{
+			Name:    "object",
+			Result:  CheckResultFail,
+			Message: fmt.Sprintf("%s %s/%s already exists and would be overwritten", obj.GetKind(), obj.GetNamespace(), obj.GetName()),
+		}
+		if isCreatedByTool(existing) {
+			check.Result = CheckResultWarn
+			check.Message = fmt.Sprintf("%s %s/%s was created by an earlier apply and will be updated", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+		}
+
+		checks = append(checks, check)
+	}
+
+	if len(checks) == 0 {
+		checks = append(checks, PrerequisiteCheck{
+			Name:    "object",
+			Result:  CheckResultPass,
+			Message: fmt.Sprintf("None of the %d migrated objects exist yet", len(objects)),
+		})
+	}
+
+	return checks, nil
+}
+
+// PrintPrerequisiteChecks prints the result of each check as a table.
+func (c *Cluster) PrintPrerequisiteChecks(checks []PrerequisiteCheck) {
+	if len(checks) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(c.stdout(), 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "CHECK\tRESULT\tMESSAGE")
+	for _, check := range checks {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, check.Result, check.Message)
+	}
+
+	_ = w.Flush()
+}
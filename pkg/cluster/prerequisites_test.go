@@ -0,0 +1,117 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newOrganization(name string) *unstructured.Unstructured {
+	organization := &unstructured.Unstructured{}
+	organization.SetGroupVersionKind(organizationGVK)
+	organization.SetName(name)
+	return organization
+}
+
+func newAppCatalogEntry(catalog string, namespace string, appName string, version string) *app.AppCatalogEntry {
+	return &app.AppCatalogEntry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s-%s", catalog, appName, version),
+			Namespace: namespace,
+			Labels:    map[string]string{catalogLabel: catalog},
+		},
+		Spec: app.AppCatalogEntrySpec{
+			AppName: appName,
+			Version: version,
+			Catalog: app.AppCatalogEntrySpecCatalog{Name: catalog, Namespace: namespace},
+		},
+	}
+}
+
+func TestCheckDestination(t *testing.T) {
+	c := newTestCluster(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "org-capa-migration-testing"}},
+		newOrganization("capa-migration-testing"),
+		&app.Catalog{ObjectMeta: metav1.ObjectMeta{Name: "giantswarm", Namespace: "giantswarm"}},
+		&app.Catalog{ObjectMeta: metav1.ObjectMeta{Name: "customer", Namespace: "org-capa-migration-testing"}},
+		newAppCatalogEntry("giantswarm", "giantswarm", "loki", "0.1.0"),
+		newAppCatalogEntry("customer", "org-capa-migration-testing", "cert-manager", "1.0.0"),
+	)
+
+	certManager := newTestApp("cert-manager", "customer", "1.0.0")
+	certManager.Spec.Namespace = "kube-system"
+	certManager.Spec.CatalogNamespace = testOrgNamespace
+	c.Apps = []app.App{newTestApp("loki", "giantswarm", "0.1.0"), certManager}
+
+	checks, err := c.CheckDestination()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(checks) != 7 {
+		t.Fatalf("Number of checks is wrong. Is: %d; Want: %d", len(checks), 7)
+	}
+	for _, check := range checks {
+		if check.Result != CheckResultPass {
+			t.Fatalf("Check %s did not pass. Is: %s; Want: %s (%s)", check.Name, check.Result, CheckResultPass, check.Message)
+		}
+	}
+}
+
+func TestCheckDestinationFailing(t *testing.T) {
+	c := newTestCluster(
+		&app.Catalog{ObjectMeta: metav1.ObjectMeta{Name: "giantswarm", Namespace: "default"}},
+		newAppCatalogEntry("giantswarm", "default", "loki", "0.2.0"),
+		newAppCatalogEntry("giantswarm", "default", "loki", "0.1.1"),
+		&app.App{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cabbage01-loki",
+				Namespace: "org-capa-migration-testing",
+				Labels:    map[string]string{createdLabel: "true"},
+			},
+		},
+		&app.App{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cabbage01-cert-manager",
+				Namespace: "org-capa-migration-testing",
+			},
+		},
+	)
+
+	certManager := newTestApp("cert-manager", "customer", "1.0.0")
+	certManager.Spec.Namespace = "kube-system"
+	certManager.Spec.CatalogNamespace = testOrgNamespace
+	c.Apps = []app.App{newTestApp("loki", "giantswarm", "0.1.0"), certManager}
+
+	checks, err := c.CheckDestination()
+	if !errors.Is(err, prerequisitesFailed) {
+		t.Fatalf("Error is wrong. Is: %v; Want: %s", err, prerequisitesFailed)
+	}
+
+	want := []struct {
+		name   string
+		result CheckResult
+	}{
+		{name: "namespace", result: CheckResultFail},
+		{name: "organization", result: CheckResultFail},
+		{name: "catalog", result: CheckResultPass},
+		{name: "catalog", result: CheckResultFail},
+		{name: "version", result: CheckResultWarn},
+		{name: "object", result: CheckResultWarn},
+		{name: "object", result: CheckResultFail},
+	}
+
+	if len(checks) != len(want) {
+		t.Fatalf("Number of checks is wrong. Is: %d; Want: %d", len(checks), len(want))
+	}
+	for i, check := range checks {
+		if check.Name != want[i].name || check.Result != want[i].result {
+			t.Fatalf("Check %d is wrong. Is: %s %s; Want: %s %s (%s)", i, check.Name, check.Result, want[i].name, want[i].result, check.Message)
+		}
+	}
+}
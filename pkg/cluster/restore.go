@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RestoreSnapshot applies every object of the snapshot at path to the source
+// MC. Config is restored before the apps using it, apps are restored in the
+// order of their dependencies. Objects which still exist are overwritten with
+// the state of the snapshot.
+func (c *Cluster) RestoreSnapshot(path string) ([]AppliedObject, error) {
+	objects, err := readSnapshot(path)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	objects, err = applyOrder(objects)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var restored []AppliedObject
+	for _, obj := range objects {
+		result, err := restoreObject(c.SrcMC.KubernetesClient, obj)
+		if err != nil {
+			return restored, microerror.Mask(err)
+		}
+
+		_, _ = fmt.Fprintf(c.stdout(), "%s/%s/%s %s\n", obj.GetKind(), obj.GetNamespace(), obj.GetName(), result)
+		restored = append(restored, AppliedObject{
+			Kind:      obj.GetKind(),
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Result:    result,
+		})
+	}
+
+	return restored, nil
+}
+
+// restoreObject applies a single object with server-side apply. Unlike
+// applyObject it does not label the object, it is restored as it was.
+func restoreObject(k8sClient client.Client, obj *unstructured.Unstructured) (ApplyResult, error) {
+	ctx := context.TODO()
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+
+	result := ApplyResultConfigured
+	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if errors.IsNotFound(err) {
+		result = ApplyResultCreated
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	patch := obj.DeepCopy()
+	stripServerMetadata(patch)
+
+	err = k8sClient.Patch(ctx, patch, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if result == ApplyResultConfigured && patch.GetResourceVersion() == existing.GetResourceVersion() {
+		result = ApplyResultUnchanged
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/context"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRestoreSnapshot(t *testing.T) {
+	src := &Cluster{
+		WcName: "wc1",
+		SrcMC: &ManagementCluster{
+			Name:             "gauss",
+			Namespace:        "wc1",
+			KubernetesClient: newApplyFakeClient(snapshotSourceObjects()...),
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.tgz")
+	_, err := src.SnapshotWC(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	c := &Cluster{
+		SrcMC: &ManagementCluster{
+			Name:             "gauss",
+			KubernetesClient: newApplyFakeClient(),
+		},
+	}
+
+	restored, err := c.RestoreSnapshot(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(restored) != 3 {
+		t.Fatalf("Number of restored objects is wrong. Is: %d; Want: %d", len(restored), 3)
+	}
+
+	if restored[len(restored)-1].Kind != "App" {
+		t.Fatalf("Apps should be restored last. Is: %s", restored[len(restored)-1].Kind)
+	}
+
+	for _, obj := range restored {
+		if obj.Result != ApplyResultCreated {
+			t.Fatalf("Result of %s/%s is wrong. Is: %s; Want: %s", obj.Kind, obj.Name, obj.Result, ApplyResultCreated)
+		}
+	}
+
+	var secret corev1.Secret
+	err = c.SrcMC.KubernetesClient.Get(context.TODO(), client.ObjectKey{Namespace: "giantswarm", Name: "loki-shared"}, &secret)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if string(secret.Data["token"]) != "secret" {
+		t.Fatalf("Secret data is wrong. Is: %s; Want: %s", secret.Data["token"], "secret")
+	}
+
+	if secret.Labels[createdLabel] != "" {
+		t.Fatalf("Restored objects should not carry the %s label", createdLabel)
+	}
+
+	restored, err = c.RestoreSnapshot(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// restoring again must not fail on the existing objects
+	for _, obj := range restored {
+		if obj.Result == ApplyResultCreated {
+			t.Fatalf("%s/%s should not be created twice", obj.Kind, obj.Name)
+		}
+	}
+}
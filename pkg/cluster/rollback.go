@@ -0,0 +1,113 @@
+package cluster
+
+import (
+	"context"
+	"slices"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RollbackResult describes what happened to a single object during rollback.
+type RollbackResult string
+
+const (
+	RollbackResultDeleted     RollbackResult = "deleted"
+	RollbackResultWouldDelete RollbackResult = "would delete"
+	RollbackResultNotFound    RollbackResult = "not found"
+	RollbackResultSkipped     RollbackResult = "skipped, not created by app-migration-cli"
+)
+
+// RolledBackObject is the outcome of rolling back a single object of the
+// dump file.
+type RolledBackObject struct {
+	Kind      string         `json:"kind"`
+	Name      string         `json:"name"`
+	Namespace string         `json:"namespace"`
+	Result    RollbackResult `json:"result"`
+}
+
+// RollbackCAPIApps deletes the objects of the dump file from the destination
+// MC in the reverse order of apply: apps are deleted before their config, so
+// app-operator does not reconcile apps with missing config, and dependent apps
+// before the apps they depend on. Objects which were not created by apply are
+// never deleted. With dryRun set, nothing is deleted. It stops before the next
+// object when ctx is cancelled.
+func (c *Cluster) RollbackCAPIApps(ctx context.Context, filename string, dryRun bool) ([]RolledBackObject, error) {
+	// secrets are only deleted, so there is no need to decrypt them
+	_, objects, err := c.readBundleFile(filename)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	objects, err = rollbackOrder(objects)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var rolledBack []RolledBackObject
+	for _, obj := range objects {
+		if ctx.Err() != nil {
+			return rolledBack, microerror.Mask(ctx.Err())
+		}
+
+		result, err := rollbackObject(ctx, c.DstMC.KubernetesClient, obj, dryRun)
+		if err != nil {
+			return rolledBack, microerror.Mask(err)
+		}
+
+		rolledBack = append(rolledBack, RolledBackObject{
+			Kind:      obj.GetKind(),
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Result:    result,
+		})
+	}
+
+	return rolledBack, nil
+}
+
+// rollbackOrder returns the objects in reverse apply order, so apps are
+// deleted before the apps they depend on and before all config.
+func rollbackOrder(objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	ordered, err := applyOrder(objects)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	slices.Reverse(ordered)
+
+	return ordered, nil
+}
+
+func rollbackObject(ctx context.Context, k8sClient client.Client, obj *unstructured.Unstructured, dryRun bool) (RollbackResult, error) {
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(obj.GroupVersionKind())
+
+	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), live)
+	if errors.IsNotFound(err) {
+		return RollbackResultNotFound, nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if !isCreatedByTool(live) {
+		return RollbackResultSkipped, nil
+	}
+
+	if dryRun {
+		return RollbackResultWouldDelete, nil
+	}
+
+	uid := live.GetUID()
+	err = k8sClient.Delete(ctx, live, client.Preconditions{UID: &uid})
+	if errors.IsNotFound(err) {
+		return RollbackResultNotFound, nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return RollbackResultDeleted, nil
+}
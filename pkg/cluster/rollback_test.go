@@ -0,0 +1,117 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/backoff"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const rollbackTestDump = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cabbage01-loki-user-values
+  namespace: org-capa-migration-testing
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: cabbage01-preexisting
+  namespace: org-capa-migration-testing
+---
+apiVersion: application.giantswarm.io/v1alpha1
+kind: App
+metadata:
+  name: cabbage01-loki
+  namespace: org-capa-migration-testing
+spec:
+  catalog: giantswarm
+  name: loki
+  namespace: loki
+  version: 0.1.0
+---
+`
+
+const rollbackTestNeverApplied = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cabbage01-never-applied
+  namespace: org-capa-migration-testing
+---
+`
+
+// Test that only objects created by apply are deleted, apps first
+func TestRollbackCAPIApps(t *testing.T) {
+	const wcName = "cabbage01"
+	const orgNamespace = "org-capa-migration-testing"
+
+	initObjs := append(prerequisiteObjects(wcName, orgNamespace),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-preexisting", Namespace: orgNamespace}},
+	)
+	k8sClient := newApplyFakeClient(initObjs...)
+
+	c := Cluster{
+		WcName:       wcName,
+		OrgNamespace: orgNamespace,
+		SrcMC:        &ManagementCluster{Name: "foo"},
+		DstMC:        &ManagementCluster{Name: "bar", KubernetesClient: k8sClient},
+		BackOff:      backoff.NewMaxRetries(0, 0),
+	}
+
+	_, err := c.ApplyCAPIApps(context.TODO(), writeDumpFile(t, rollbackTestDump))
+	if err != nil {
+		t.Fatalf("Apply failed: %s", err)
+	}
+
+	filename := writeDumpFile(t, rollbackTestDump+rollbackTestNeverApplied)
+
+	rolledBack, err := c.RollbackCAPIApps(context.TODO(), filename, true)
+	if err != nil {
+		t.Fatalf("Rollback dry-run failed: %s", err)
+	}
+
+	want := []RolledBackObject{
+		{Kind: "App", Name: "cabbage01-loki", Namespace: orgNamespace, Result: RollbackResultWouldDelete},
+		{Kind: "ConfigMap", Name: "cabbage01-never-applied", Namespace: orgNamespace, Result: RollbackResultNotFound},
+		{Kind: "Secret", Name: "cabbage01-preexisting", Namespace: orgNamespace, Result: RollbackResultSkipped},
+		{Kind: "ConfigMap", Name: "cabbage01-loki-user-values", Namespace: orgNamespace, Result: RollbackResultWouldDelete},
+	}
+
+	if len(rolledBack) != len(want) {
+		t.Fatalf("Rolled back objects not correct; Is: %d; Want: %d", len(rolledBack), len(want))
+	}
+	for i := range want {
+		if rolledBack[i] != want[i] {
+			t.Fatalf("Rollback of object %d not correct; Is: %+v; Want: %+v", i, rolledBack[i], want[i])
+		}
+	}
+
+	var cm corev1.ConfigMap
+	err = k8sClient.Get(context.TODO(), client.ObjectKey{Name: "cabbage01-loki-user-values", Namespace: orgNamespace}, &cm)
+	if err != nil {
+		t.Fatalf("Dry-run must not delete objects: %s", err)
+	}
+
+	rolledBack, err = c.RollbackCAPIApps(context.TODO(), filename, false)
+	if err != nil {
+		t.Fatalf("Rollback failed: %s", err)
+	}
+	if rolledBack[0].Result != RollbackResultDeleted || rolledBack[3].Result != RollbackResultDeleted {
+		t.Fatalf("Objects created by apply should be deleted; Is: %+v", rolledBack)
+	}
+
+	err = k8sClient.Get(context.TODO(), client.ObjectKey{Name: "cabbage01-loki-user-values", Namespace: orgNamespace}, &cm)
+	if !errors.IsNotFound(err) {
+		t.Fatalf("ConfigMap created by apply should be deleted; Is: %v", err)
+	}
+
+	var secret corev1.Secret
+	err = k8sClient.Get(context.TODO(), client.ObjectKey{Name: "cabbage01-preexisting", Namespace: orgNamespace}, &secret)
+	if err != nil {
+		t.Fatalf("Secret not created by apply must not be deleted: %s", err)
+	}
+}
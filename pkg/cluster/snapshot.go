@@ -0,0 +1,485 @@
+package cluster
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	apps "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"golang.org/x/net/context"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// kinds read from a snapshot, everything else is ignored
+var snapshotKinds = []string{
+	"App",
+	"ConfigMap",
+	"Secret",
+}
+
+// snapshot files are recognized by their extension
+var snapshotExtensions = []string{
+	".yaml",
+	".yml",
+	".json",
+}
+
+// NewFromSnapshot returns a Cluster whose source MC is served from the
+// snapshot at path instead of a live connection. It has no destination MC
+// client, so it can only be used to prepare a migration.
+func NewFromSnapshot(srcMC string, dstMC string, path string) (*Cluster, error) {
+	k8sClient, err := NewSnapshotClient(path)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &Cluster{
+		SrcMC: &ManagementCluster{
+			Name:             srcMC,
+			KubernetesClient: k8sClient,
+		},
+		DstMC: &ManagementCluster{
+			Name: dstMC,
+		},
+	}, nil
+}
+
+// NewSnapshotClient returns a read-only client serving the Apps, ConfigMaps
+// and Secrets found in the yaml and json files below path, eg. exported with
+// `kubectl get -o yaml` or written by SnapshotWC. Lists are unpacked.
+// Snapshot archives are read as well.
+func NewSnapshotClient(path string) (client.Client, error) {
+	manifests, err := readSnapshot(path)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, manifest := range manifests {
+		err := validateSnapshotObject(manifest)
+		if err != nil {
+			return nil, microerror.Maskf(invalidSnapshot, "Could not read %s %s/%s: %s", manifest.GetKind(), manifest.GetNamespace(), manifest.GetName(), err)
+		}
+	}
+
+	return newSnapshotClient(manifests), nil
+}
+
+// readSnapshot returns the objects of a snapshot directory or archive.
+// Objects found more than once are deduplicated, the last one wins.
+func readSnapshot(path string) ([]*unstructured.Unstructured, error) {
+	var keys []string
+	objects := map[string]*unstructured.Unstructured{}
+
+	add := func(name string, r io.Reader) error {
+		fileObjects, err := readSnapshotFile(r)
+		if err != nil {
+			return microerror.Maskf(invalidSnapshot, "Could not read %s: %s", name, err)
+		}
+
+		for _, obj := range fileObjects {
+			key := strings.Join([]string{obj.GetKind(), obj.GetNamespace(), obj.GetName()}, "/")
+			if _, found := objects[key]; !found {
+				keys = append(keys, key)
+			}
+			objects[key] = obj
+		}
+
+		return nil
+	}
+
+	var err error
+	if isSnapshotArchive(path) {
+		err = walkSnapshotArchive(path, add)
+	} else {
+		err = walkSnapshotDir(path, add)
+	}
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if len(keys) == 0 {
+		return nil, microerror.Maskf(invalidSnapshot, "No %s found in snapshot %s", strings.Join(snapshotKinds, ", "), path)
+	}
+
+	var result []*unstructured.Unstructured
+	for _, key := range keys {
+		result = append(result, objects[key])
+	}
+
+	return result, nil
+}
+
+func walkSnapshotDir(dir string, fn func(name string, r io.Reader) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		if d.IsDir() || !isSnapshotFile(path) {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		defer func() { _ = f.Close() }()
+
+		return fn(path, f)
+	})
+}
+
+func walkSnapshotArchive(path string, fn func(name string, r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return microerror.Maskf(invalidSnapshot, "Could not read archive %s: %s", path, err)
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return microerror.Maskf(invalidSnapshot, "Could not read archive %s: %s", path, err)
+		}
+
+		if header.Typeflag != tar.TypeReg || !isSnapshotFile(header.Name) {
+			continue
+		}
+
+		err = fn(fmt.Sprintf("%s:%s", path, header.Name), tr)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+}
+
+func readSnapshotFile(r io.Reader) ([]*unstructured.Unstructured, error) {
+	manifests, err := decodeManifests(r)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var items []*unstructured.Unstructured
+	for _, manifest := range manifests {
+		if !manifest.IsList() {
+			items = append(items, manifest)
+			continue
+		}
+
+		list, err := manifest.ToList()
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for i := range list.Items {
+			items = append(items, &list.Items[i])
+		}
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, item := range items {
+		if slices.Contains(snapshotKinds, item.GetKind()) {
+			objects = append(objects, item)
+		}
+	}
+
+	return objects, nil
+}
+
+func isSnapshotFile(path string) bool {
+	return slices.Contains(snapshotExtensions, strings.ToLower(filepath.Ext(path)))
+}
+
+func isSnapshotArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// validateSnapshotObject checks that obj can be converted to its typed
+// object, so reading it from the snapshot client does not fail later.
+func validateSnapshotObject(obj *unstructured.Unstructured) error {
+	typed, err := scheme.New(obj.GroupVersionKind())
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// SnapshotObject is an object written to a snapshot.
+type SnapshotObject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// SnapshotWC writes every App, ConfigMap and Secret of the WC namespace on the
+// source MC, plus the config in other namespaces referenced by the apps, to
+// path. Names and namespaces are kept, server-managed metadata and status are
+// removed. path is written as a gzipped tar archive if it ends with .tar.gz
+// or .tgz, as a directory otherwise.
+func (c *Cluster) SnapshotWC(path string) ([]SnapshotObject, error) {
+	objects, err := c.snapshotObjects()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if isSnapshotArchive(path) {
+		err = writeSnapshotArchive(path, objects)
+	} else {
+		err = writeSnapshotDir(path, objects)
+	}
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var written []SnapshotObject
+	for _, obj := range objects {
+		written = append(written, SnapshotObject{
+			Kind:      obj.GetKind(),
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+		})
+	}
+
+	return written, nil
+}
+
+func (c *Cluster) snapshotObjects() ([]*unstructured.Unstructured, error) {
+	ctx := context.TODO()
+
+	var objects []*unstructured.Unstructured
+	for _, gvk := range []schema.GroupVersionKind{
+		apps.SchemeGroupVersion.WithKind("App"),
+		corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+		corev1.SchemeGroupVersion.WithKind("Secret"),
+	} {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+		err := c.SrcMC.KubernetesClient.List(ctx, list, client.InNamespace(c.SrcMC.Namespace))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			obj.SetGroupVersionKind(gvk)
+
+			if isGeneratedObject(obj) {
+				continue
+			}
+
+			objects = append(objects, obj)
+		}
+	}
+
+	// config referenced by the apps might live in other namespaces
+	var references []*unstructured.Unstructured
+	for _, obj := range objects {
+		if obj.GetKind() != "App" {
+			continue
+		}
+
+		var application apps.App
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &application)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, ref := range appConfigReferences(application) {
+			if ref.GetNamespace() == "" || ref.GetNamespace() == c.SrcMC.Namespace || containsObject(references, ref) {
+				continue
+			}
+
+			err := c.SrcMC.KubernetesClient.Get(ctx, client.ObjectKeyFromObject(ref), ref)
+			if apierrors.IsNotFound(err) {
+				_, _ = fmt.Fprintf(c.stdout(), "%s %s/%s referenced by app %s/%s not found, skipping\n", ref.GetKind(), ref.GetNamespace(), ref.GetName(), application.Namespace, application.Name)
+				continue
+			} else if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			references = append(references, ref)
+		}
+	}
+	objects = append(objects, references...)
+
+	for _, obj := range objects {
+		stripServerMetadata(obj)
+	}
+
+	return objects, nil
+}
+
+// appConfigReferences returns empty objects for all config maps and secrets
+// referenced by an app.
+func appConfigReferences(application apps.App) []*unstructured.Unstructured {
+	var refs []*unstructured.Unstructured
+
+	add := func(kind string, name string, namespace string) {
+		if name == "" {
+			return
+		}
+
+		ref := &unstructured.Unstructured{}
+		ref.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(kind))
+		ref.SetName(name)
+		ref.SetNamespace(namespace)
+
+		refs = append(refs, ref)
+	}
+
+	add("ConfigMap", application.Spec.Config.ConfigMap.Name, application.Spec.Config.ConfigMap.Namespace)
+	add("Secret", application.Spec.Config.Secret.Name, application.Spec.Config.Secret.Namespace)
+	add("ConfigMap", application.Spec.UserConfig.ConfigMap.Name, application.Spec.UserConfig.ConfigMap.Namespace)
+	add("Secret", application.Spec.UserConfig.Secret.Name, application.Spec.UserConfig.Secret.Namespace)
+	add("Secret", application.Spec.KubeConfig.Secret.Name, application.Spec.KubeConfig.Secret.Namespace)
+
+	for _, extraConfig := range application.Spec.ExtraConfigs {
+		switch strings.ToLower(extraConfig.Kind) {
+		case configmapType:
+			add("ConfigMap", extraConfig.Name, extraConfig.Namespace)
+		case secretType:
+			add("Secret", extraConfig.Name, extraConfig.Namespace)
+		}
+	}
+
+	return refs
+}
+
+func containsObject(objects []*unstructured.Unstructured, obj *unstructured.Unstructured) bool {
+	return slices.ContainsFunc(objects, func(o *unstructured.Unstructured) bool {
+		return o.GetKind() == obj.GetKind() && o.GetNamespace() == obj.GetNamespace() && o.GetName() == obj.GetName()
+	})
+}
+
+// isGeneratedObject returns true for objects kubernetes creates in every
+// namespace. Restoring them would conflict with the controllers owning them.
+func isGeneratedObject(obj *unstructured.Unstructured) bool {
+	switch obj.GetKind() {
+	case "ConfigMap":
+		return obj.GetName() == "kube-root-ca.crt"
+	case "Secret":
+		secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+		return secretType == string(corev1.SecretTypeServiceAccountToken)
+	}
+
+	return false
+}
+
+// stripServerMetadata removes status and the metadata set by the API server,
+// so the object can be created again. Owner references are removed as well,
+// the owners will not exist with the same UID anymore.
+func stripServerMetadata(obj *unstructured.Unstructured) {
+	delete(obj.Object, "status")
+
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink", "ownerReferences", "deletionTimestamp", "deletionGracePeriodSeconds"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+}
+
+// snapshotFileName returns the path of an object within a snapshot.
+func snapshotFileName(obj *unstructured.Unstructured) string {
+	return filepath.Join(obj.GetNamespace(), fmt.Sprintf("%s-%s.yaml", strings.ToLower(obj.GetKind()), obj.GetName()))
+}
+
+func writeSnapshotDir(dir string, objects []*unstructured.Unstructured) error {
+	for _, obj := range objects {
+		data, err := k8syaml.Marshal(obj.Object)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		path := filepath.Join(dir, snapshotFileName(obj))
+
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = os.WriteFile(path, data, 0600)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+func writeSnapshotArchive(path string, objects []*unstructured.Unstructured) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	for _, obj := range objects {
+		data, err := k8syaml.Marshal(obj.Object)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = tw.WriteHeader(&tar.Header{
+			Name:    filepath.ToSlash(snapshotFileName(obj)),
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		_, err = tw.Write(data)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = gz.Close()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
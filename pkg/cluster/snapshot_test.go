@@ -0,0 +1,263 @@
+package cluster
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"golang.org/x/net/context"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const snapshotAppList = `apiVersion: v1
+kind: List
+items:
+- apiVersion: application.giantswarm.io/v1alpha1
+  kind: App
+  metadata:
+    name: loki
+    namespace: wc1
+    resourceVersion: "1234"
+  spec:
+    catalog: giantswarm
+    name: loki
+    namespace: loki
+    version: 0.1.0
+    kubeConfig:
+      inCluster: false
+    userConfig:
+      configMap:
+        name: loki-user-values
+        namespace: wc1
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: ignored
+    namespace: wc1
+`
+
+const snapshotConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: loki-user-values
+  namespace: wc1
+data:
+  values: |
+    foo: bar
+`
+
+func writeSnapshot(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	return dir
+}
+
+func TestNewFromSnapshot(t *testing.T) {
+	dir := writeSnapshot(t, map[string]string{
+		"apps.yaml":             snapshotAppList,
+		"configs/loki.yaml":     snapshotConfigMap,
+		"configs/README.md":     "not a manifest",
+		"configs/loki-dup.yaml": snapshotConfigMap,
+	})
+
+	c, err := NewFromSnapshot("gauss", "golem", dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c.WcName = "wc1"
+	c.OrgNamespace = "org-foo"
+
+	var appList app.AppList
+	err = c.SrcMC.KubernetesClient.List(context.TODO(), &appList, client.MatchingFields{"metadata.namespace": "wc1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(appList.Items) != 1 {
+		t.Fatalf("Number of apps is wrong. Is: %d; Want: %d", len(appList.Items), 1)
+	}
+	c.Apps = appList.Items
+
+	yaml, err := c.migrateApps()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(yaml) != 2 {
+		t.Fatalf("Number of migrated objects is wrong. Is: %d; Want: %d", len(yaml), 2)
+	}
+
+	if !strings.Contains(string(yaml[0]), "name: wc1-loki-user-values") || !strings.Contains(string(yaml[0]), "foo: bar") {
+		t.Fatalf("User values should be migrated from the snapshot. Is: %s", yaml[0])
+	}
+
+	var cm corev1.ConfigMap
+	err = c.SrcMC.KubernetesClient.Get(context.TODO(), client.ObjectKey{Namespace: "wc1", Name: "loki-user-values"}, &cm)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestSnapshotClientReadOnly(t *testing.T) {
+	dir := writeSnapshot(t, map[string]string{
+		"apps.yaml":         snapshotAppList,
+		"configs/loki.yaml": snapshotConfigMap,
+	})
+
+	k8sClient, err := NewSnapshotClient(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var cmList corev1.ConfigMapList
+	err = k8sClient.List(context.TODO(), &cmList, client.InNamespace("wc1"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(cmList.Items) != 1 || cmList.Items[0].Data["values"] != "foo: bar\n" {
+		t.Fatalf("Config maps are wrong. Is: %v", cmList.Items)
+	}
+
+	var appList app.AppList
+	err = k8sClient.List(context.TODO(), &appList, client.MatchingLabels{"foo": "bar"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(appList.Items) != 0 {
+		t.Fatalf("Apps should be filtered by labels. Is: %v", appList.Items)
+	}
+
+	err = k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: "wc1", Name: "missing"}, &corev1.Secret{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("Missing object should not be found. Is: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "wc1", Name: "new"}}
+	for verb, write := range map[string]func() error{
+		"create": func() error { return k8sClient.Create(context.TODO(), cm) },
+		"update": func() error { return k8sClient.Update(context.TODO(), cm) },
+		"delete": func() error { return k8sClient.Delete(context.TODO(), cm) },
+		"patch":  func() error { return k8sClient.Patch(context.TODO(), cm, client.Merge) },
+		"status": func() error { return k8sClient.Status().Update(context.TODO(), cm) },
+	} {
+		err := write()
+		if !errors.Is(err, readOnlySnapshot) {
+			t.Fatalf("%s should be rejected. Is: %v", verb, err)
+		}
+	}
+}
+
+func TestNewFromSnapshotEmpty(t *testing.T) {
+	dir := writeSnapshot(t, map[string]string{
+		"deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: foo\n",
+	})
+
+	_, err := NewFromSnapshot("gauss", "golem", dir)
+	if !errors.Is(err, invalidSnapshot) {
+		t.Fatalf("Snapshot without apps should be rejected. Is: %v", err)
+	}
+}
+
+// snapshotSourceObjects returns a WC namespace with an app referencing config
+// in its own and in another namespace.
+func snapshotSourceObjects() []runtime.Object {
+	ownerRef := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "1234"}
+
+	return []runtime.Object{
+		&app.App{
+			ObjectMeta: metav1.ObjectMeta{Name: "loki", Namespace: "wc1"},
+			Spec: app.AppSpec{
+				Name:    "loki",
+				Catalog: "giantswarm",
+				UserConfig: app.AppSpecUserConfig{
+					ConfigMap: app.AppSpecUserConfigConfigMap{Name: "loki-user-values", Namespace: "wc1"},
+				},
+				ExtraConfigs: []app.AppExtraConfig{
+					{Kind: "secret", Name: "loki-shared", Namespace: "giantswarm"},
+					{Kind: "configMap", Name: "missing", Namespace: "giantswarm"},
+				},
+			},
+			Status: app.AppStatus{Version: "0.1.0"},
+		},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "loki-user-values", Namespace: "wc1", OwnerReferences: []metav1.OwnerReference{ownerRef}}, Data: map[string]string{"values": "foo: bar"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "wc1"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "default-token", Namespace: "wc1"}, Type: corev1.SecretTypeServiceAccountToken},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "loki-shared", Namespace: "giantswarm"}, Data: map[string][]byte{"token": []byte("secret")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "giantswarm"}},
+	}
+}
+
+func TestSnapshotWC(t *testing.T) {
+	for _, name := range []string{"snapshot", "snapshot.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			c := &Cluster{
+				WcName: "wc1",
+				SrcMC: &ManagementCluster{
+					Name:             "gauss",
+					Namespace:        "wc1",
+					KubernetesClient: newApplyFakeClient(snapshotSourceObjects()...),
+				},
+			}
+
+			path := filepath.Join(t.TempDir(), name)
+			written, err := c.SnapshotWC(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			want := []SnapshotObject{
+				{Kind: "App", Name: "loki", Namespace: "wc1"},
+				{Kind: "ConfigMap", Name: "loki-user-values", Namespace: "wc1"},
+				{Kind: "Secret", Name: "loki-shared", Namespace: "giantswarm"},
+			}
+			if len(written) != len(want) {
+				t.Fatalf("Written objects are wrong. Is: %v; Want: %v", written, want)
+			}
+			for i := range want {
+				if written[i] != want[i] {
+					t.Fatalf("Written object is wrong. Is: %v; Want: %v", written[i], want[i])
+				}
+			}
+
+			objects, err := readSnapshot(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if len(objects) != len(want) {
+				t.Fatalf("Number of objects read back is wrong. Is: %d; Want: %d", len(objects), len(want))
+			}
+
+			for _, obj := range objects {
+				if obj.GetResourceVersion() != "" || obj.GetUID() != "" || len(obj.GetOwnerReferences()) != 0 {
+					t.Fatalf("Server-managed metadata should be removed from %s/%s", obj.GetKind(), obj.GetName())
+				}
+
+				if _, found := obj.Object["status"]; found {
+					t.Fatalf("Status should be removed from %s/%s", obj.GetKind(), obj.GetName())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package cluster
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"golang.org/x/net/context"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// snapshotClient serves the objects of a snapshot. It only supports reads,
+// all writes fail with readOnlySnapshot. List supports namespace, label and
+// field selectors on metadata.name and metadata.namespace.
+type snapshotClient struct {
+	objects    map[schema.GroupVersionKind]map[client.ObjectKey]*unstructured.Unstructured
+	restMapper meta.RESTMapper
+}
+
+var _ client.Client = &snapshotClient{}
+
+func newSnapshotClient(objects []*unstructured.Unstructured) *snapshotClient {
+	restMapper := meta.NewDefaultRESTMapper(nil)
+
+	c := &snapshotClient{
+		objects:    map[schema.GroupVersionKind]map[client.ObjectKey]*unstructured.Unstructured{},
+		restMapper: restMapper,
+	}
+
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		if _, found := c.objects[gvk]; !found {
+			c.objects[gvk] = map[client.ObjectKey]*unstructured.Unstructured{}
+			restMapper.Add(gvk, meta.RESTScopeNamespace)
+		}
+
+		c.objects[gvk][client.ObjectKeyFromObject(obj)] = obj
+	}
+
+	return c
+}
+
+func (c *snapshotClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	gvk, err := c.GroupVersionKindFor(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	stored, found := c.objects[gvk][key]
+	if !found {
+		resource, _ := meta.UnsafeGuessKindToResource(gvk)
+		return apierrors.NewNotFound(resource.GroupResource(), key.Name)
+	}
+
+	return fromSnapshotObject(stored, obj)
+}
+
+func (c *snapshotClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listGVK, err := c.GroupVersionKindFor(list)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	gvk := listGVK.GroupVersion().WithKind(strings.TrimSuffix(listGVK.Kind, "List"))
+
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+
+	var keys []client.ObjectKey
+	for key, obj := range c.objects[gvk] {
+		if listOpts.Namespace != "" && key.Namespace != listOpts.Namespace {
+			continue
+		}
+		if listOpts.LabelSelector != nil && !listOpts.LabelSelector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		if listOpts.FieldSelector != nil && !listOpts.FieldSelector.Matches(fields.Set{"metadata.name": key.Name, "metadata.namespace": key.Namespace}) {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Name < keys[j].Name
+	})
+
+	if unstructuredList, ok := list.(*unstructured.UnstructuredList); ok {
+		unstructuredList.Items = nil
+		for _, key := range keys {
+			unstructuredList.Items = append(unstructuredList.Items, *c.objects[gvk][key].DeepCopy())
+		}
+		return nil
+	}
+
+	var items []runtime.Object
+	for _, key := range keys {
+		typed, err := scheme.New(gvk)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		obj, ok := typed.(client.Object)
+		if !ok {
+			return microerror.Maskf(invalidSnapshot, "%T is not a kubernetes object", typed)
+		}
+
+		err = fromSnapshotObject(c.objects[gvk][key], obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		items = append(items, obj)
+	}
+
+	return meta.SetList(list, items)
+}
+
+func (c *snapshotClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return readOnlyError("create", obj)
+}
+
+func (c *snapshotClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return readOnlyError("delete", obj)
+}
+
+func (c *snapshotClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return readOnlyError("update", obj)
+}
+
+func (c *snapshotClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return readOnlyError("patch", obj)
+}
+
+func (c *snapshotClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return readOnlyError("delete", obj)
+}
+
+func (c *snapshotClient) Status() client.SubResourceWriter {
+	return &snapshotSubResourceClient{}
+}
+
+func (c *snapshotClient) SubResource(subResource string) client.SubResourceClient {
+	return &snapshotSubResourceClient{}
+}
+
+func (c *snapshotClient) Scheme() *runtime.Scheme {
+	return scheme
+}
+
+func (c *snapshotClient) RESTMapper() meta.RESTMapper {
+	return c.restMapper
+}
+
+func (c *snapshotClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	if _, ok := obj.(runtime.Unstructured); ok {
+		return obj.GetObjectKind().GroupVersionKind(), nil
+	}
+
+	return apiutil.GVKForObject(obj, scheme)
+}
+
+func (c *snapshotClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	return apiutil.IsObjectNamespaced(obj, scheme, c.restMapper)
+}
+
+// snapshotSubResourceClient rejects all status and subresource calls, the
+// snapshot has no subresources.
+type snapshotSubResourceClient struct{}
+
+func (c *snapshotSubResourceClient) Get(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceGetOption) error {
+	return microerror.Maskf(readOnlySnapshot, "snapshots have no subresources")
+}
+
+func (c *snapshotSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return readOnlyError("create", obj)
+}
+
+func (c *snapshotSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return readOnlyError("update", obj)
+}
+
+func (c *snapshotSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return readOnlyError("patch", obj)
+}
+
+func readOnlyError(verb string, obj client.Object) error {
+	return microerror.Maskf(readOnlySnapshot, "Could not %s %s/%s, snapshots are read-only", verb, obj.GetNamespace(), obj.GetName())
+}
+
+// fromSnapshotObject copies a stored object into obj, which may be typed or
+// unstructured.
+func fromSnapshotObject(stored *unstructured.Unstructured, obj client.Object) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.Object = stored.DeepCopy().Object
+		return nil
+	}
+
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(stored.DeepCopy().Object, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
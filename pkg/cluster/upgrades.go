@@ -0,0 +1,172 @@
+package cluster
+
+import (
+	"os"
+
+	"github.com/blang/semver/v4"
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
+)
+
+// UpgradeRules rewrite the version and catalog of apps which do not run on
+// CAPI clusters in the version installed on the source MC.
+//
+//	catalogs:
+//	  control-plane-catalog: giantswarm
+//	apps:
+//	- name: loki
+//	  minVersion: 0.8.0
+//	- name: cert-manager-app
+//	  catalog: giantswarm
+//	  versions:
+//	    "2.15.3": "3.7.0"
+type UpgradeRules struct {
+	// Catalogs maps the catalogs of the source MC to the ones used on the
+	// destination MC.
+	Catalogs map[string]string `json:"catalogs,omitempty"`
+	Apps     []AppUpgrade      `json:"apps,omitempty"`
+}
+
+// AppUpgrade rewrites the apps with the given spec.name.
+type AppUpgrade struct {
+	Name string `json:"name"`
+
+	// Versions maps exact versions to the version used on the destination
+	// MC. It takes precedence over MinVersion.
+	Versions map[string]string `json:"versions,omitempty"`
+	// MinVersion is used for all apps with an older version.
+	MinVersion string `json:"minVersion,omitempty"`
+	// Catalog moves the app to another catalog, it takes precedence over
+	// the catalogs mapping.
+	Catalog string `json:"catalog,omitempty"`
+}
+
+// LoadUpgradeRules reads and validates an upgrade rules file.
+func LoadUpgradeRules(filename string) (*UpgradeRules, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var rules UpgradeRules
+	err = k8syaml.UnmarshalStrict(data, &rules)
+	if err != nil {
+		return nil, microerror.Maskf(invalidUpgradeRules, "Could not parse upgrade rules %s: %s", filename, err)
+	}
+
+	err = rules.Validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &rules, nil
+}
+
+func (r *UpgradeRules) Validate() error {
+	for from, to := range r.Catalogs {
+		if to == "" {
+			return microerror.Maskf(invalidUpgradeRules, "catalogs.%s must not be empty", from)
+		}
+	}
+
+	names := map[string]int{}
+	for i, upgrade := range r.Apps {
+		if upgrade.Name == "" {
+			return microerror.Maskf(invalidUpgradeRules, "apps[%d].name must not be empty", i)
+		}
+
+		if j, found := names[upgrade.Name]; found {
+			return microerror.Maskf(invalidUpgradeRules, "apps[%d].name %q is already used by apps[%d]", i, upgrade.Name, j)
+		}
+		names[upgrade.Name] = i
+
+		if upgrade.MinVersion == "" && len(upgrade.Versions) == 0 && upgrade.Catalog == "" {
+			return microerror.Maskf(invalidUpgradeRules, "apps[%d] must set minVersion, versions or catalog", i)
+		}
+
+		if upgrade.MinVersion != "" {
+			_, err := semver.ParseTolerant(upgrade.MinVersion)
+			if err != nil {
+				return microerror.Maskf(invalidUpgradeRules, "apps[%d].minVersion is invalid: %s", i, err)
+			}
+		}
+
+		for from, to := range upgrade.Versions {
+			if to == "" {
+				return microerror.Maskf(invalidUpgradeRules, "apps[%d].versions.%s must not be empty", i, from)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Upgrade returns the catalog and version the app is migrated with.
+func (r *UpgradeRules) Upgrade(application app.App) (string, string) {
+	catalog := application.Spec.Catalog
+	version := application.Spec.Version
+
+	if to, found := r.Catalogs[catalog]; found {
+		catalog = to
+	}
+
+	for _, upgrade := range r.Apps {
+		if upgrade.Name != application.Spec.Name {
+			continue
+		}
+
+		if upgrade.Catalog != "" {
+			catalog = upgrade.Catalog
+		}
+
+		if to, found := upgrade.Versions[application.Spec.Version]; found {
+			version = to
+		} else if upgrade.MinVersion != "" && isOlder(application.Spec.Version, upgrade.MinVersion) {
+			version = upgrade.MinVersion
+		}
+	}
+
+	return catalog, version
+}
+
+// isOlder returns true if version is older than minVersion. Versions which
+// are not semver are never older.
+func isOlder(version string, minVersion string) bool {
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return false
+	}
+
+	min, err := semver.ParseTolerant(minVersion)
+	if err != nil {
+		return false
+	}
+
+	return v.LT(min)
+}
+
+// upgradeApp returns the catalog and version the app is migrated with
+// according to c.UpgradeRules.
+func (c *Cluster) upgradeApp(application app.App) (string, string) {
+	if c.UpgradeRules == nil {
+		return application.Spec.Catalog, application.Spec.Version
+	}
+
+	return c.UpgradeRules.Upgrade(application)
+}
+
+// ReportUpgrades adds the catalog and version changes of the apps in c.Apps
+// to the report.
+func (c *Cluster) ReportUpgrades(report *apps.Report) {
+	for _, application := range c.Apps {
+		catalog, version := c.upgradeApp(application)
+		if catalog == application.Spec.Catalog && version == application.Spec.Version {
+			continue
+		}
+
+		report.SetUpgrade(application, catalog, version)
+	}
+}
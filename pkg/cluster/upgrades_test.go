@@ -0,0 +1,146 @@
+package cluster
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
+)
+
+func TestUpgrade(t *testing.T) {
+	rules := &UpgradeRules{
+		Catalogs: map[string]string{"control-plane-catalog": "giantswarm"},
+		Apps: []AppUpgrade{
+			{Name: "loki", MinVersion: "0.8.0"},
+			{Name: "cert-manager-app", Catalog: "cert-manager", Versions: map[string]string{"2.15.3": "3.7.0"}, MinVersion: "3.0.0"},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		app     app.App
+		catalog string
+		version string
+	}{
+		{
+			name:    "older than min version",
+			app:     newTestApp("loki", "giantswarm", "0.4.2"),
+			catalog: "giantswarm",
+			version: "0.8.0",
+		},
+		{
+			name:    "newer than min version",
+			app:     newTestApp("loki", "giantswarm", "v0.9.0"),
+			catalog: "giantswarm",
+			version: "v0.9.0",
+		},
+		{
+			name:    "explicit version",
+			app:     newTestApp("cert-manager-app", "control-plane-catalog", "2.15.3"),
+			catalog: "cert-manager",
+			version: "3.7.0",
+		},
+		{
+			name:    "min version without explicit version",
+			app:     newTestApp("cert-manager-app", "giantswarm", "2.10.0"),
+			catalog: "cert-manager",
+			version: "3.0.0",
+		},
+		{
+			name:    "catalog rename",
+			app:     newTestApp("kyverno", "control-plane-catalog", "1.0.0"),
+			catalog: "giantswarm",
+			version: "1.0.0",
+		},
+		{
+			name:    "no rule",
+			app:     newTestApp("kyverno", "giantswarm", "1.0.0"),
+			catalog: "giantswarm",
+			version: "1.0.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			catalog, version := rules.Upgrade(tc.app)
+
+			if catalog != tc.catalog {
+				t.Fatalf("Catalog is wrong. Is: %s; Want: %s", catalog, tc.catalog)
+			}
+			if version != tc.version {
+				t.Fatalf("Version is wrong. Is: %s; Want: %s", version, tc.version)
+			}
+		})
+	}
+}
+
+func TestLoadUpgradeRules(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "upgrade-rules.yaml")
+	err := os.WriteFile(filename, []byte(`catalogs:
+  control-plane-catalog: giantswarm
+apps:
+- name: loki
+  minVersion: 0.8.0
+`), 0600)
+	if err != nil {
+		t.Fatalf("Could not write rules: %s", err)
+	}
+
+	rules, err := LoadUpgradeRules(filename)
+	if err != nil {
+		t.Fatalf("Could not load rules: %s", err)
+	}
+
+	c := newTestCluster()
+	c.UpgradeRules = rules
+	c.Apps = []app.App{
+		newTestApp("loki", "control-plane-catalog", "0.4.2"),
+		newTestApp("kyverno", "giantswarm", "1.0.0"),
+	}
+
+	yamlText, err := c.migrateApps()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var migratedApp app.App
+	err = yaml.Unmarshal(yamlText[0], &migratedApp)
+	if err != nil {
+		t.Fatalf(`Could not unmarshal yaml: %s`, err)
+	}
+
+	if migratedApp.Spec.Catalog != "giantswarm" || migratedApp.Spec.Version != "0.8.0" {
+		t.Fatalf("App not upgraded; Is: %s %s; Want: %s %s", migratedApp.Spec.Catalog, migratedApp.Spec.Version, "giantswarm", "0.8.0")
+	}
+
+	report := apps.NewReport(c.WcName, c.Apps, nil)
+	c.ReportUpgrades(report)
+
+	if report.Apps[0].TargetCatalog != "giantswarm" || report.Apps[0].TargetVersion != "0.8.0" {
+		t.Fatalf("Upgrade not reported; Is: %+v", report.Apps[0])
+	}
+	if report.Apps[1].TargetCatalog != "" || report.Apps[1].TargetVersion != "" {
+		t.Fatalf("Unchanged app reported as upgraded; Is: %+v", report.Apps[1])
+	}
+}
+
+func TestUpgradeRulesValidate(t *testing.T) {
+	for name, rules := range map[string]UpgradeRules{
+		"empty catalog":         {Catalogs: map[string]string{"default": ""}},
+		"missing name":          {Apps: []AppUpgrade{{MinVersion: "1.0.0"}}},
+		"no upgrade":            {Apps: []AppUpgrade{{Name: "loki"}}},
+		"invalid min version":   {Apps: []AppUpgrade{{Name: "loki", MinVersion: "latest"}}},
+		"empty explicit target": {Apps: []AppUpgrade{{Name: "loki", Versions: map[string]string{"1.0.0": ""}}}},
+		"duplicate name":        {Apps: []AppUpgrade{{Name: "loki", MinVersion: "1.0.0"}, {Name: "loki", Catalog: "giantswarm"}}},
+	} {
+		err := rules.Validate()
+		if !errors.Is(err, invalidUpgradeRules) {
+			t.Fatalf("Rules with %s should be invalid; Is: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/giantswarm/microerror"
+	"github.com/pmezard/go-difflib/difflib"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
+)
+
+const (
+	ValuesActionSet        = "set"
+	ValuesActionDelete     = "delete"
+	ValuesActionReplace    = "replace"
+	ValuesActionSubstitute = "substitute"
+)
+
+// valuesKey is the key of ConfigMaps and Secrets holding the helm values of
+// an app
+const valuesKey = "values"
+
+// ValuesRules transform the values of the migrated ConfigMaps and Secrets.
+// All transformations are applied in order.
+//
+//	vars:
+//	  baseDomain: golem.gigantic.io
+//	transformations:
+//	- action: set
+//	  names: ["*-user-values"]
+//	  path: [serviceAccount, annotations, eks.amazonaws.com/role-arn]
+//	  value: "arn:aws:iam::123456789012:role/{{ .WcName }}-loki"
+//	- action: delete
+//	  path: [aws, region]
+//	- action: replace
+//	  path: [ingress, host]
+//	  from: "{{ .SourceWcName }}.k8s.gauss.eu-west-1.aws.gigantic.io"
+//	  to: "{{ .WcName }}.{{ .Vars.baseDomain }}"
+//	- action: substitute
+//	  kinds: ["ConfigMap"]
+//	  from: gauss
+//	  to: golem
+type ValuesRules struct {
+	// Vars are passed to the templates as .Vars, eg. facts about the
+	// destination MC which are not known to the tool.
+	Vars            map[string]string      `json:"vars,omitempty"`
+	Transformations []ValuesTransformation `json:"transformations"`
+}
+
+// ValuesTransformation changes the values key of ConfigMaps and Secrets.
+// Value, From and To are templates which may use .WcName, .SourceWcName,
+// .OrgNamespace, .Organization, .SourceMC, .DestinationMC and .Vars.
+type ValuesTransformation struct {
+	Action string `json:"action"`
+
+	// Kinds limits the transformation to ConfigMap or Secret, Names to the
+	// objects with these names on the source MC. Names may contain `*`
+	// wildcards. The transformation applies to all objects if they are empty.
+	Kinds []string `json:"kinds,omitempty"`
+	Names []string `json:"names,omitempty"`
+
+	// Path lists the keys leading to the value changed by set, delete and
+	// replace.
+	Path []string `json:"path,omitempty"`
+	// Value is parsed as yaml and set by set.
+	Value string `json:"value,omitempty"`
+	// From is replaced by To in the string at Path by replace, in the whole
+	// values by substitute.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// valuesTemplateData is passed to the templates of the transformations.
+type valuesTemplateData struct {
+	WcName        string
+	SourceWcName  string
+	OrgNamespace  string
+	Organization  string
+	SourceMC      string
+	DestinationMC string
+	Vars          map[string]string
+}
+
+// LoadValuesRules reads and validates a values rules file.
+func LoadValuesRules(filename string) (*ValuesRules, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var rules ValuesRules
+	err = k8syaml.UnmarshalStrict(data, &rules)
+	if err != nil {
+		return nil, microerror.Maskf(invalidValuesRules, "Could not parse values rules %s: %s", filename, err)
+	}
+
+	err = rules.Validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &rules, nil
+}
+
+func (r *ValuesRules) Validate() error {
+	for i, transformation := range r.Transformations {
+		switch transformation.Action {
+		case ValuesActionSet, ValuesActionDelete:
+			if len(transformation.Path) == 0 {
+				return microerror.Maskf(invalidValuesRules, "transformations[%d].path must not be empty", i)
+			}
+		case ValuesActionReplace:
+			if len(transformation.Path) == 0 {
+				return microerror.Maskf(invalidValuesRules, "transformations[%d].path must not be empty", i)
+			}
+			if transformation.From == "" {
+				return microerror.Maskf(invalidValuesRules, "transformations[%d].from must not be empty", i)
+			}
+		case ValuesActionSubstitute:
+			if transformation.From == "" {
+				return microerror.Maskf(invalidValuesRules, "transformations[%d].from must not be empty", i)
+			}
+		default:
+			return microerror.Maskf(invalidValuesRules, "transformations[%d].action must be %q, %q, %q or %q", i, ValuesActionSet, ValuesActionDelete, ValuesActionReplace, ValuesActionSubstitute)
+		}
+
+		for _, text := range []string{transformation.Value, transformation.From, transformation.To} {
+			_, err := template.New("values").Option("missingkey=error").Parse(text)
+			if err != nil {
+				return microerror.Maskf(invalidValuesRules, "transformations[%d] has an invalid template: %s", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// transformValues applies c.ValuesRules to the values key of a ConfigMap or
+// Secret called name on the source MC. It returns data unchanged if no
+// transformation applies, otherwise a copy with the new values. The changes
+// are printed as a diff, redacted for Secrets.
+func (c *Cluster) transformValues(kind string, name string, data map[string]string) (map[string]string, error) {
+	if c.ValuesRules == nil {
+		return data, nil
+	}
+
+	values, found := data[valuesKey]
+	if !found {
+		return data, nil
+	}
+
+	templateData := valuesTemplateData{
+		WcName:       c.DestinationWcName(),
+		SourceWcName: c.WcName,
+		OrgNamespace: c.OrgNamespace,
+		Organization: organizationFromNamespace(c.OrgNamespace),
+		Vars:         c.ValuesRules.Vars,
+	}
+	if c.SrcMC != nil {
+		templateData.SourceMC = c.SrcMC.Name
+	}
+	if c.DstMC != nil {
+		templateData.DestinationMC = c.DstMC.Name
+	}
+
+	newValues := values
+	for _, transformation := range c.ValuesRules.Transformations {
+		if len(transformation.Kinds) > 0 && !apps.MatchesAny(transformation.Kinds, kind) {
+			continue
+		}
+		if len(transformation.Names) > 0 && !apps.MatchesAny(transformation.Names, name) {
+			continue
+		}
+
+		var err error
+		newValues, err = transformation.apply(newValues, templateData)
+		if err != nil {
+			return nil, microerror.Maskf(invalidValuesRules, "Could not transform values of %s %s: %s", kind, name, err)
+		}
+	}
+
+	if newValues == values {
+		return data, nil
+	}
+
+	if kind == "Secret" {
+		_, _ = fmt.Fprintf(c.stdout(), "Values of %s %s transformed, the diff of secrets is not shown\n", kind, name)
+	} else {
+		diff, err := valuesDiff(values, newValues, fmt.Sprintf("%s/%s", kind, name))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		_, _ = fmt.Fprint(c.stdout(), diff)
+	}
+
+	newData := make(map[string]string, len(data))
+	for key, value := range data {
+		newData[key] = value
+	}
+	newData[valuesKey] = newValues
+
+	return newData, nil
+}
+
+// transformSecretValues applies transformValues to the data of a Secret.
+func (c *Cluster) transformSecretValues(name string, data map[string][]byte) (map[string][]byte, error) {
+	values, found := data[valuesKey]
+	if !found || c.ValuesRules == nil {
+		return data, nil
+	}
+
+	transformed, err := c.transformValues("Secret", name, map[string]string{valuesKey: string(values)})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if transformed[valuesKey] == string(values) {
+		return data, nil
+	}
+
+	newData := make(map[string][]byte, len(data))
+	for key, value := range data {
+		newData[key] = value
+	}
+	newData[valuesKey] = []byte(transformed[valuesKey])
+
+	return newData, nil
+}
+
+// apply returns the transformed values. Values changed by set, delete and
+// replace are parsed and marshalled again, which sorts the keys and drops
+// comments.
+func (t *ValuesTransformation) apply(values string, data valuesTemplateData) (string, error) {
+	from, err := renderValuesTemplate(t.From, data)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	to, err := renderValuesTemplate(t.To, data)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if t.Action == ValuesActionSubstitute {
+		return strings.ReplaceAll(values, from, to), nil
+	}
+
+	var parsed map[string]interface{}
+	err = k8syaml.Unmarshal([]byte(values), &parsed)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	if parsed == nil {
+		parsed = map[string]interface{}{}
+	}
+
+	parent, err := valuesParent(parsed, t.Path, t.Action == ValuesActionSet)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	key := t.Path[len(t.Path)-1]
+
+	switch t.Action {
+	case ValuesActionSet:
+		rendered, err := renderValuesTemplate(t.Value, data)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		var value interface{}
+		err = k8syaml.Unmarshal([]byte(rendered), &value)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		parent[key] = value
+
+	case ValuesActionDelete:
+		if _, found := parent[key]; !found {
+			return values, nil
+		}
+		delete(parent, key)
+
+	case ValuesActionReplace:
+		current, ok := parent[key].(string)
+		if !ok || !strings.Contains(current, from) {
+			return values, nil
+		}
+		parent[key] = strings.ReplaceAll(current, from, to)
+	}
+
+	newValues, err := k8syaml.Marshal(parsed)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return string(newValues), nil
+}
+
+// valuesParent returns the map holding the last key of path. Missing maps are
+// created if create is set, otherwise an empty map is returned for them.
+func valuesParent(values map[string]interface{}, path []string, create bool) (map[string]interface{}, error) {
+	parent := values
+	for i, key := range path[:len(path)-1] {
+		child, found := parent[key]
+		if !found {
+			if !create {
+				return map[string]interface{}{}, nil
+			}
+			child = map[string]interface{}{}
+			parent[key] = child
+		}
+
+		childMap, ok := child.(map[string]interface{})
+		if !ok {
+			return nil, microerror.Maskf(invalidValuesRules, "%s is not a map", strings.Join(path[:i+1], "."))
+		}
+		parent = childMap
+	}
+
+	return parent, nil
+}
+
+func renderValuesTemplate(text string, data valuesTemplateData) (string, error) {
+	tmpl, err := template.New("values").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, data)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return out.String(), nil
+}
+
+func valuesDiff(values string, newValues string, name string) (string, error) {
+	var out bytes.Buffer
+	err := difflib.WriteUnifiedDiff(&out, difflib.UnifiedDiff{
+		A:        difflib.SplitLines(values),
+		B:        difflib.SplitLines(newValues),
+		FromFile: "source/" + name,
+		ToFile:   "migrated/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return out.String(), nil
+}
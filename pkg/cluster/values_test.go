@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const valuesTestRules = `vars:
+  baseDomain: golem.gigantic.io
+transformations:
+- action: set
+  names: ["*-user-values"]
+  path: [serviceAccount, annotations, eks.amazonaws.com/role-arn]
+  value: "arn:aws:iam::123456789012:role/{{ .WcName }}-loki"
+- action: delete
+  path: [aws, region]
+- action: replace
+  path: [ingress, host]
+  from: "{{ .SourceWcName }}.k8s.gauss.eu-west-1.aws.gigantic.io"
+  to: "{{ .WcName }}.{{ .Vars.baseDomain }}"
+- action: substitute
+  kinds: ["Secret"]
+  from: "{{ .SourceMC }}"
+  to: "{{ .DestinationMC }}"
+`
+
+func TestTransformValues(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "values-rules.yaml")
+	err := os.WriteFile(filename, []byte(valuesTestRules), 0600)
+	if err != nil {
+		t.Fatalf("Could not write rules: %s", err)
+	}
+
+	rules, err := LoadValuesRules(filename)
+	if err != nil {
+		t.Fatalf("Could not load rules: %s", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cabbage01-loki-user-values",
+			Namespace: "cabbage01",
+		},
+		Data: map[string]string{
+			"values": "aws:\n  region: eu-west-1\n  account: \"123\"\ningress:\n  host: cabbage01.k8s.gauss.eu-west-1.aws.gigantic.io\n",
+			"other":  "gauss",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cabbage01-loki-user-secrets",
+			Namespace: "cabbage01",
+		},
+		Data: map[string][]byte{
+			"values": []byte("endpoint: https://gauss.example.com\n"),
+		},
+	}
+
+	c := Cluster{
+		WcName:       "cabbage01",
+		DstWcName:    "cabbage02",
+		OrgNamespace: "org-capa-migration-testing",
+		ValuesRules:  rules,
+		SrcMC: &ManagementCluster{
+			Name:             "gauss",
+			KubernetesClient: fake.NewFakeClient(configMap, secret),
+		},
+		DstMC: &ManagementCluster{Name: "golem"},
+		Apps: []app.App{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-loki", Namespace: "cabbage01"},
+				Spec: app.AppSpec{
+					Name:      "loki",
+					Namespace: "loki",
+					Version:   "0.1.0",
+					Catalog:   "giantswarm",
+					UserConfig: app.AppSpecUserConfig{
+						ConfigMap: app.AppSpecUserConfigConfigMap{Name: "cabbage01-loki-user-values", Namespace: "cabbage01"},
+						Secret:    app.AppSpecUserConfigSecret{Name: "cabbage01-loki-user-secrets", Namespace: "cabbage01"},
+					},
+				},
+			},
+		},
+	}
+
+	yamlText, err := c.migrateApps()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var migratedConfigMap corev1.ConfigMap
+	var migratedSecret corev1.Secret
+	for _, obj := range yamlText {
+		switch {
+		case isSecretYaml(obj):
+			err = yaml.Unmarshal(obj, &migratedSecret)
+		case migratedConfigMap.Name == "":
+			err = yaml.Unmarshal(obj, &migratedConfigMap)
+		}
+		if err != nil {
+			t.Fatalf(`Could not unmarshal yaml: %s`, err)
+		}
+	}
+
+	wantValues := `aws:
+  account: "123"
+ingress:
+  host: cabbage02.golem.gigantic.io
+serviceAccount:
+  annotations:
+    eks.amazonaws.com/role-arn: arn:aws:iam::123456789012:role/cabbage02-loki
+`
+	if migratedConfigMap.Data["values"] != wantValues {
+		t.Fatalf("Values not transformed; Is: %s; Want: %s", migratedConfigMap.Data["values"], wantValues)
+	}
+	if migratedConfigMap.Data["other"] != "gauss" {
+		t.Fatalf("Other key changed; Is: %s; Want: %s", migratedConfigMap.Data["other"], "gauss")
+	}
+
+	wantSecretValues := "endpoint: https://golem.example.com\n"
+	if string(migratedSecret.Data["values"]) != wantSecretValues {
+		t.Fatalf("Secret values not transformed; Is: %s; Want: %s", migratedSecret.Data["values"], wantSecretValues)
+	}
+}
+
+func TestTransformValuesNotAMap(t *testing.T) {
+	c := Cluster{
+		WcName: "cabbage01",
+		ValuesRules: &ValuesRules{Transformations: []ValuesTransformation{
+			{Action: ValuesActionSet, Path: []string{"aws", "region"}, Value: "eu-central-1"},
+		}},
+	}
+
+	_, err := c.transformValues("ConfigMap", "loki-user-values", map[string]string{"values": "aws: foo\n"})
+	if !errors.Is(err, invalidValuesRules) {
+		t.Fatalf("Error is wrong. Is: %v; Want: %s", err, invalidValuesRules)
+	}
+}
+
+func TestValuesRulesValidate(t *testing.T) {
+	for name, transformation := range map[string]ValuesTransformation{
+		"invalid action":          {Action: "move", Path: []string{"foo"}},
+		"set without path":        {Action: ValuesActionSet, Value: "foo"},
+		"replace without from":    {Action: ValuesActionReplace, Path: []string{"foo"}, To: "bar"},
+		"substitute without from": {Action: ValuesActionSubstitute, To: "bar"},
+		"invalid template":        {Action: ValuesActionSubstitute, From: "{{ .WcName", To: "bar"},
+	} {
+		rules := ValuesRules{Transformations: []ValuesTransformation{transformation}}
+
+		err := rules.Validate()
+		if !errors.Is(err, invalidValuesRules) {
+			t.Fatalf("Transformation with %s should be invalid; Is: %v", name, err)
+		}
+	}
+}
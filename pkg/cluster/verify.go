@@ -0,0 +1,152 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/backoff"
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AppState summarizes the release status of a migrated app.
+type AppState string
+
+const (
+	AppStateDeployed AppState = "deployed"
+	AppStateFailed   AppState = "failed"
+	AppStatePending  AppState = "pending"
+)
+
+// release states reported by app-operator which will not resolve without
+// manual intervention
+var failedReleaseStates = []string{
+	"failed",
+	"already-exists",
+}
+
+// AppVerification is the state of a single app of the dump file on the
+// destination MC.
+type AppVerification struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	State     AppState `json:"state"`
+
+	// Release and Reason are taken from the app's release status as reported
+	// by app-operator.
+	Release string `json:"release,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// VerifyCAPIApps polls every app of the dump file on the destination MC until
+// all of them are deployed. It gives up early if an app failed and times out
+// according to c.BackOff or when ctx is cancelled. The last known state of
+// each app is returned in any case.
+func (c *Cluster) VerifyCAPIApps(ctx context.Context, filename string) ([]AppVerification, error) {
+	// only the names of the apps are read, so there is no need to decrypt
+	// secrets
+	_, objects, err := c.readBundleFile(filename)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var apps []*unstructured.Unstructured
+	for _, obj := range objects {
+		if obj.GetKind() == "App" {
+			apps = append(apps, obj)
+		}
+	}
+
+	var verifications []AppVerification
+	verify := func() error {
+		verifications = nil
+
+		var deployed, failed, pending int
+		for _, obj := range apps {
+			verification, err := verifyApp(ctx, c.DstMC.KubernetesClient, obj)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			switch verification.State {
+			case AppStateDeployed:
+				deployed++
+			case AppStateFailed:
+				failed++
+			default:
+				pending++
+			}
+
+			verifications = append(verifications, verification)
+		}
+
+		_, _ = fmt.Fprintf(c.stdout(), "Apps on %s: %d deployed, %d failed, %d pending\n", c.DstMC.Name, deployed, failed, pending)
+
+		if failed > 0 {
+			return backoff.Permanent(microerror.Maskf(appsFailed, "%d apps failed to deploy", failed))
+		}
+
+		if pending > 0 {
+			return microerror.Maskf(appsPending, "%d apps are not deployed yet", pending)
+		}
+
+		return nil
+	}
+
+	err = retry(ctx, verify, c.BackOff)
+	if err != nil {
+		return verifications, microerror.Mask(err)
+	}
+
+	return verifications, nil
+}
+
+func verifyApp(ctx context.Context, k8sClient client.Client, obj *unstructured.Unstructured) (AppVerification, error) {
+	verification := AppVerification{
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		State:     AppStatePending,
+	}
+
+	var application app.App
+	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), &application)
+	if errors.IsNotFound(err) {
+		verification.Reason = "App not found on the destination MC"
+		return verification, nil
+	} else if err != nil {
+		return AppVerification{}, microerror.Mask(err)
+	}
+
+	verification.Release = application.Status.Release.Status
+	verification.Reason = application.Status.Release.Reason
+
+	switch {
+	case verification.Release == "deployed":
+		verification.State = AppStateDeployed
+	case slices.Contains(failedReleaseStates, verification.Release):
+		verification.State = AppStateFailed
+	}
+
+	return verification, nil
+}
+
+// PrintAppVerifications prints the state of each app as a table.
+func (c *Cluster) PrintAppVerifications(verifications []AppVerification) {
+	if len(verifications) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(c.stdout(), 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tSTATE\tRELEASE\tREASON")
+	for _, v := range verifications {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", v.Namespace, v.Name, v.State, v.Release, v.Reason)
+	}
+
+	_ = w.Flush()
+}
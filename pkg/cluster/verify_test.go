@@ -0,0 +1,110 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/backoff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const verifyTestDump = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cabbage01-loki-user-values
+  namespace: org-capa-migration-testing
+---
+apiVersion: application.giantswarm.io/v1alpha1
+kind: App
+metadata:
+  name: cabbage01-loki
+  namespace: org-capa-migration-testing
+---
+apiVersion: application.giantswarm.io/v1alpha1
+kind: App
+metadata:
+  name: cabbage01-promtail
+  namespace: org-capa-migration-testing
+---
+`
+
+func TestVerifyCAPIAppsDeployed(t *testing.T) {
+	c := newTestCluster(
+		&app.App{ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-loki", Namespace: testOrgNamespace}, Status: app.AppStatus{Release: app.AppStatusRelease{Status: "deployed"}}},
+		&app.App{ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-promtail", Namespace: testOrgNamespace}, Status: app.AppStatus{Release: app.AppStatusRelease{Status: "deployed"}}},
+	)
+
+	verifications, err := c.VerifyCAPIApps(context.TODO(), writeDumpFile(t, verifyTestDump))
+	if err != nil {
+		t.Fatalf("Verify failed: %s", err)
+	}
+
+	if len(verifications) != 2 {
+		t.Fatalf("Only apps should be verified; Is: %d; Want: %d", len(verifications), 2)
+	}
+
+	for _, verification := range verifications {
+		if verification.State != AppStateDeployed {
+			t.Fatalf("App %s state not correct; Is: %s; Want: %s", verification.Name, verification.State, AppStateDeployed)
+		}
+	}
+}
+
+func TestVerifyCAPIAppsFailed(t *testing.T) {
+	c := newTestCluster(
+		&app.App{ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-loki", Namespace: testOrgNamespace}, Status: app.AppStatus{Release: app.AppStatusRelease{Status: "failed", Reason: "chart values are invalid"}}},
+		&app.App{ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-promtail", Namespace: testOrgNamespace}, Status: app.AppStatus{Release: app.AppStatusRelease{Status: "deployed"}}},
+	)
+
+	verifications, err := c.VerifyCAPIApps(context.TODO(), writeDumpFile(t, verifyTestDump))
+	if !errors.Is(err, appsFailed) {
+		t.Fatalf("Failed apps should return appsFailed; Is: %v", err)
+	}
+
+	if verifications[0].State != AppStateFailed || verifications[0].Reason != "chart values are invalid" {
+		t.Fatalf("Failed app not reported correctly; Is: %+v", verifications[0])
+	}
+}
+
+func TestVerifyCAPIAppsPending(t *testing.T) {
+	c := newTestCluster(
+		&app.App{ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-loki", Namespace: testOrgNamespace}, Status: app.AppStatus{Release: app.AppStatusRelease{Status: "pending-install"}}},
+	)
+
+	verifications, err := c.VerifyCAPIApps(context.TODO(), writeDumpFile(t, verifyTestDump))
+	if !errors.Is(err, appsPending) {
+		t.Fatalf("Pending apps should return appsPending; Is: %v", err)
+	}
+
+	for _, verification := range verifications {
+		if verification.State != AppStatePending {
+			t.Fatalf("App %s state not correct; Is: %s; Want: %s", verification.Name, verification.State, AppStatePending)
+		}
+	}
+}
+
+func TestVerifyCAPIAppsCancelled(t *testing.T) {
+	c := newTestCluster(
+		&app.App{ObjectMeta: metav1.ObjectMeta{Name: "cabbage01-loki", Namespace: testOrgNamespace}, Status: app.AppStatus{Release: app.AppStatusRelease{Status: "pending-install"}}},
+	)
+	c.BackOff = backoff.NewConstant(time.Minute, 10*time.Second)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := c.VerifyCAPIApps(ctx, writeDumpFile(t, verifyTestDump))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Error is wrong. Is: %v; Want: %s", err, context.Canceled)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("Verify did not stop on cancel; Took: %s", time.Since(start))
+	}
+}
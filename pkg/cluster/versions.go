@@ -0,0 +1,174 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	app "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// catalogLabel is set by app-operator on the AppCatalogEntries of a catalog
+const catalogLabel = "application.giantswarm.io/catalog"
+
+// catalogIndex maps the app names of a catalog to their indexed versions.
+type catalogIndex map[string][]string
+
+// CheckAppVersions checks on the destination MC if the catalog of each app in
+// c.Apps has an AppCatalogEntry for the app and the version it is migrated
+// with. Apps which are missing in the catalog fail the check. app-operator
+// only indexes the latest versions of an app, so a missing version is a
+// warning which proposes the nearest indexed version. Apps of catalogs which
+// do not exist are skipped, they are reported by CheckDestination.
+func (c *Cluster) CheckAppVersions() ([]PrerequisiteCheck, error) {
+	k8sClient := c.DstMC.KubernetesClient
+
+	var checks []PrerequisiteCheck
+
+	indexes := map[string]catalogIndex{}
+	for _, application := range c.Apps {
+		catalog, version := c.upgradeApp(application)
+		namespaces := catalogNamespaces
+		if application.Spec.CatalogNamespace != "" {
+			namespaces = []string{application.Spec.CatalogNamespace}
+		}
+
+		key := fmt.Sprintf("%v/%s", namespaces, catalog)
+		index, found := indexes[key]
+		if !found {
+			var err error
+			index, err = loadCatalogIndex(k8sClient, catalog, namespaces)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			indexes[key] = index
+		}
+
+		if index == nil {
+			continue
+		}
+
+		checks = append(checks, checkAppVersion(index, catalog, application.Spec.Name, version))
+	}
+
+	return checks, nil
+}
+
+// WarnAppVersions runs CheckAppVersions and records every app which is not
+// available on the destination MC as a warning.
+func (c *Cluster) WarnAppVersions() error {
+	checks, err := c.CheckAppVersions()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, check := range checks {
+		if check.Result != CheckResultPass {
+			c.warn("%s", check.Message)
+		}
+	}
+
+	return nil
+}
+
+// loadCatalogIndex lists the AppCatalogEntries of the catalog in the first of
+// the namespaces it exists in. It returns nil if the catalog does not exist.
+func loadCatalogIndex(k8sClient client.Client, catalog string, namespaces []string) (catalogIndex, error) {
+	for _, namespace := range namespaces {
+		var catalogCR app.Catalog
+		err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: catalog, Namespace: namespace}, &catalogCR)
+		if client.IgnoreNotFound(err) != nil {
+			return nil, microerror.Mask(err)
+		} else if err != nil {
+			continue
+		}
+
+		var entries app.AppCatalogEntryList
+		err = k8sClient.List(context.TODO(), &entries, client.InNamespace(namespace), client.MatchingLabels{catalogLabel: catalog})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		index := catalogIndex{}
+		for _, entry := range entries.Items {
+			index[entry.Spec.AppName] = append(index[entry.Spec.AppName], entry.Spec.Version)
+		}
+
+		return index, nil
+	}
+
+	return nil, nil
+}
+
+func checkAppVersion(index catalogIndex, catalog string, appName string, version string) PrerequisiteCheck {
+	versions, found := index[appName]
+	if !found {
+		return PrerequisiteCheck{
+			Name:    "version",
+			Result:  CheckResultFail,
+			Message: fmt.Sprintf("App %s not found in catalog %s", appName, catalog),
+		}
+	}
+
+	for _, v := range versions {
+		if v == version {
+			return PrerequisiteCheck{
+				Name:    "version",
+				Result:  CheckResultPass,
+				Message: fmt.Sprintf("App %s %s is available in catalog %s", appName, version, catalog),
+			}
+		}
+	}
+
+	message := fmt.Sprintf("App %s %s is not indexed in catalog %s", appName, version, catalog)
+	if nearest := nearestVersion(versions, version); nearest != "" {
+		message = fmt.Sprintf("%s, nearest available version is %s", message, nearest)
+	}
+
+	return PrerequisiteCheck{
+		Name:    "version",
+		Result:  CheckResultWarn,
+		Message: message,
+	}
+}
+
+// nearestVersion returns the lowest of the versions which is newer than
+// version, or the newest one if there is none. Versions which are not semver
+// are ignored. It returns an empty string if no version can be proposed.
+func nearestVersion(versions []string, version string) string {
+	type parsedVersion struct {
+		raw     string
+		version semver.Version
+	}
+
+	var parsed []parsedVersion
+	for _, v := range versions {
+		p, err := semver.ParseTolerant(v)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, parsedVersion{raw: v, version: p})
+	}
+
+	if len(parsed) == 0 {
+		return ""
+	}
+
+	sort.Slice(parsed, func(i, j int) bool {
+		return parsed[i].version.LT(parsed[j].version)
+	})
+
+	wanted, err := semver.ParseTolerant(version)
+	if err == nil {
+		for _, p := range parsed {
+			if p.version.GT(wanted) {
+				return p.raw
+			}
+		}
+	}
+
+	return parsed[len(parsed)-1].raw
+}
@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"testing"
+)
+
+func TestCheckAppVersion(t *testing.T) {
+	index := catalogIndex{
+		"loki": {"0.1.0", "0.3.0", "0.2.1"},
+	}
+
+	testCases := []struct {
+		name    string
+		appName string
+		version string
+		result  CheckResult
+		message string
+	}{
+		{
+			name:    "indexed",
+			appName: "loki",
+			version: "0.2.1",
+			result:  CheckResultPass,
+			message: "App loki 0.2.1 is available in catalog giantswarm",
+		},
+		{
+			name:    "next newer version",
+			appName: "loki",
+			version: "0.2.0",
+			result:  CheckResultWarn,
+			message: "App loki 0.2.0 is not indexed in catalog giantswarm, nearest available version is 0.2.1",
+		},
+		{
+			name:    "newer than all versions",
+			appName: "loki",
+			version: "1.0.0",
+			result:  CheckResultWarn,
+			message: "App loki 1.0.0 is not indexed in catalog giantswarm, nearest available version is 0.3.0",
+		},
+		{
+			name:    "app not in catalog",
+			appName: "promtail",
+			version: "0.1.0",
+			result:  CheckResultFail,
+			message: "App promtail not found in catalog giantswarm",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := checkAppVersion(index, "giantswarm", tc.appName, tc.version)
+
+			if check.Result != tc.result {
+				t.Fatalf("Result is wrong. Is: %s; Want: %s", check.Result, tc.result)
+			}
+			if check.Message != tc.message {
+				t.Fatalf("Message is wrong. Is: %s; Want: %s", check.Message, tc.message)
+			}
+		})
+	}
+}
+
+func TestNearestVersion(t *testing.T) {
+	nearest := nearestVersion([]string{"v1.2.0", "latest", "v1.10.0"}, "v1.3.0")
+	if nearest != "v1.10.0" {
+		t.Fatalf("Nearest version is wrong. Is: %s; Want: %s", nearest, "v1.10.0")
+	}
+
+	nearest = nearestVersion([]string{"latest"}, "v1.3.0")
+	if nearest != "" {
+		t.Fatalf("Nearest version is wrong. Is: %s; Want: empty", nearest)
+	}
+}
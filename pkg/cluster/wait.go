@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
+)
+
+// wcPlaceholder is replaced with the name of the WC on the destination MC in
+// the names of prerequisites
+const wcPlaceholder = "{wc}"
+
+var (
+	// prerequisiteInterval is the time between two checks of the
+	// prerequisites
+	prerequisiteInterval = 5 * time.Second
+	// prerequisiteStatusInterval is the time after which the missing
+	// prerequisites are printed again, even if they did not change
+	prerequisiteStatusInterval = time.Minute
+)
+
+// prerequisiteKinds are the kinds of objects which can be waited for.
+var prerequisiteKinds = map[string]schema.GroupVersionKind{
+	"configmap": {Version: "v1", Kind: "ConfigMap"},
+	"secret":    {Version: "v1", Kind: "Secret"},
+	"app":       {Group: "application.giantswarm.io", Version: "v1alpha1", Kind: "App"},
+}
+
+// Prerequisite is an object which must exist on the destination MC before the
+// apps are applied. Name may contain {wc}, Namespace defaults to the org
+// namespace.
+type Prerequisite struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// DefaultPrerequisites returns the config created with the WC, which the
+// migrated apps reference.
+func DefaultPrerequisites() []Prerequisite {
+	return []Prerequisite{
+		{Kind: "configmap", Name: apps.ClusterValuesName(wcPlaceholder)},
+		{Kind: "secret", Name: apps.ClusterValuesName(wcPlaceholder)},
+		{Kind: "secret", Name: apps.KubeconfigName(wcPlaceholder)},
+	}
+}
+
+// ParsePrerequisite parses `kind/name` or `kind/namespace/name`, eg.
+// `secret/{wc}-kubeconfig`. kind is one of configmap, secret and app.
+func ParsePrerequisite(s string) (Prerequisite, error) {
+	parts := strings.Split(s, "/")
+
+	var p Prerequisite
+	switch len(parts) {
+	case 2:
+		p = Prerequisite{Kind: strings.ToLower(parts[0]), Name: parts[1]}
+	case 3:
+		p = Prerequisite{Kind: strings.ToLower(parts[0]), Namespace: parts[1], Name: parts[2]}
+	default:
+		return Prerequisite{}, microerror.Maskf(invalidPrerequisite, "%q must be kind/name or kind/namespace/name", s)
+	}
+
+	if _, found := prerequisiteKinds[p.Kind]; !found {
+		return Prerequisite{}, microerror.Maskf(invalidPrerequisite, "kind of %q must be one of configmap, secret or app", s)
+	}
+
+	if p.Name == "" {
+		return Prerequisite{}, microerror.Maskf(invalidPrerequisite, "name of %q must not be empty", s)
+	}
+
+	return p, nil
+}
+
+func (p Prerequisite) String() string {
+	return fmt.Sprintf("%s %s/%s", prerequisiteKinds[p.Kind].Kind, p.Namespace, p.Name)
+}
+
+// prerequisites returns c.Prerequisites, or the default ones, for the
+// destination WC.
+func (c *Cluster) prerequisites() []Prerequisite {
+	prerequisites := c.Prerequisites
+	if prerequisites == nil {
+		prerequisites = DefaultPrerequisites()
+	}
+
+	var resolved []Prerequisite
+	for _, p := range prerequisites {
+		if p.Namespace == "" {
+			p.Namespace = c.OrgNamespace
+		}
+		p.Name = strings.ReplaceAll(p.Name, wcPlaceholder, c.DestinationWcName())
+
+		resolved = append(resolved, p)
+	}
+
+	return resolved
+}
+
+// WaitForPrerequisites polls the destination MC until all prerequisites
+// exist. It gives up after c.PrerequisiteTimeout, if set, or when ctx is
+// cancelled. The missing prerequisites are printed whenever they change.
+func (c *Cluster) WaitForPrerequisites(ctx context.Context) error {
+	if c.PrerequisiteTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.PrerequisiteTimeout)
+		defer cancel()
+	}
+
+	prerequisites := c.prerequisites()
+	start := time.Now()
+
+	var lastMissing []string
+	var lastPrinted time.Time
+	for {
+		missing, err := c.missingPrerequisites(ctx, prerequisites)
+		if err != nil && ctx.Err() == nil {
+			_, _ = fmt.Fprintf(c.stdout(), "Error checking prerequisites on %s: %s\n", c.DstMC.Name, err)
+		} else if err == nil && len(missing) == 0 {
+			_, _ = color.New(color.FgYellow).Fprintf(c.stdout(), "All prerequisites are found on %s for app migration\n", c.DstMC.Name)
+			return nil
+		}
+
+		if err == nil && (!slices.Equal(missing, lastMissing) || time.Since(lastPrinted) >= prerequisiteStatusInterval) {
+			_, _ = fmt.Fprintf(c.stdout(), "Waiting for %d of %d prerequisites on %s (%s): %s\n", len(missing), len(prerequisites), c.DstMC.Name, time.Since(start).Round(time.Second), strings.Join(missing, ", "))
+			lastMissing = missing
+			lastPrinted = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded && len(lastMissing) == 0 {
+				return microerror.Maskf(prerequisitesTimeout, "prerequisites on %s could not be checked within %s", c.DstMC.Name, c.PrerequisiteTimeout)
+			} else if ctx.Err() == context.DeadlineExceeded {
+				return microerror.Maskf(prerequisitesTimeout, "prerequisites on %s still missing after %s: %s", c.DstMC.Name, c.PrerequisiteTimeout, strings.Join(lastMissing, ", "))
+			}
+			return microerror.Mask(ctx.Err())
+		case <-time.After(prerequisiteInterval):
+		}
+	}
+}
+
+// missingPrerequisites returns the prerequisites which do not exist yet.
+func (c *Cluster) missingPrerequisites(ctx context.Context, prerequisites []Prerequisite) ([]string, error) {
+	var missing []string
+	for _, p := range prerequisites {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(prerequisiteKinds[p.Kind])
+
+		err := c.DstMC.KubernetesClient.Get(ctx, client.ObjectKey{Name: p.Name, Namespace: p.Namespace}, obj)
+		if errors.IsNotFound(err) {
+			missing = append(missing, p.String())
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return missing, nil
+}
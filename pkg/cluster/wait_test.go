@@ -0,0 +1,132 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParsePrerequisite(t *testing.T) {
+	testCases := []struct {
+		input        string
+		prerequisite Prerequisite
+		err          error
+	}{
+		{
+			input:        "secret/{wc}-kubeconfig",
+			prerequisite: Prerequisite{Kind: "secret", Name: "{wc}-kubeconfig"},
+		},
+		{
+			input:        "ConfigMap/giantswarm/{wc}-values",
+			prerequisite: Prerequisite{Kind: "configmap", Namespace: "giantswarm", Name: "{wc}-values"},
+		},
+		{
+			input: "deployment/foo",
+			err:   invalidPrerequisite,
+		},
+		{
+			input: "secret",
+			err:   invalidPrerequisite,
+		},
+		{
+			input: "secret/",
+			err:   invalidPrerequisite,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			prerequisite, err := ParsePrerequisite(tc.input)
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("Error is wrong. Is: %v; Want: %s", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if prerequisite != tc.prerequisite {
+				t.Fatalf("Prerequisite is wrong. Is: %+v; Want: %+v", prerequisite, tc.prerequisite)
+			}
+		})
+	}
+}
+
+// fastPrerequisiteInterval polls the prerequisites often for the duration of
+// the test.
+func fastPrerequisiteInterval(t *testing.T) {
+	interval := prerequisiteInterval
+	prerequisiteInterval = 10 * time.Millisecond
+	t.Cleanup(func() { prerequisiteInterval = interval })
+}
+
+func TestWaitForPrerequisites(t *testing.T) {
+	fastPrerequisiteInterval(t)
+	c := newTestCluster(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cabbage02-cluster-values", Namespace: testOrgNamespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cabbage02-cluster-values", Namespace: testOrgNamespace}},
+	)
+	c.DstWcName = "cabbage02"
+	c.PrerequisiteTimeout = time.Second
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = c.DstMC.KubernetesClient.Create(context.TODO(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "cabbage02-kubeconfig", Namespace: testOrgNamespace},
+		})
+	}()
+
+	err := c.WaitForPrerequisites(context.TODO())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestWaitForPrerequisitesTimeout(t *testing.T) {
+	fastPrerequisiteInterval(t)
+	c := newTestCluster(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cabbage02-kubeconfig", Namespace: testOrgNamespace}},
+	)
+	c.DstWcName = "cabbage02"
+	c.PrerequisiteTimeout = 50 * time.Millisecond
+	c.Prerequisites = []Prerequisite{
+		{Kind: "secret", Name: "{wc}-kubeconfig"},
+		{Kind: "configmap", Namespace: "giantswarm", Name: "{wc}-values"},
+	}
+
+	err := c.WaitForPrerequisites(context.TODO())
+	if !errors.Is(err, prerequisitesTimeout) {
+		t.Fatalf("Error is wrong. Is: %v; Want: %s", err, prerequisitesTimeout)
+	}
+
+	want := "ConfigMap giantswarm/cabbage02-values"
+	missing, err := c.missingPrerequisites(context.TODO(), c.prerequisites())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(missing) != 1 || missing[0] != want {
+		t.Fatalf("Missing prerequisites are wrong. Is: %v; Want: %s", missing, want)
+	}
+}
+
+func TestWaitForPrerequisitesCancelled(t *testing.T) {
+	fastPrerequisiteInterval(t)
+	c := newTestCluster()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	err := c.WaitForPrerequisites(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Error is wrong. Is: %v; Want: %s", err, context.Canceled)
+	}
+}
@@ -0,0 +1,9 @@
+package output
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidFormatError = &microerror.Error{
+	Kind: "invalidFormatError",
+}
@@ -0,0 +1,151 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-migration-cli/pkg/apps"
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+)
+
+// Format selects how the outcome of a command is printed.
+type Format string
+
+const (
+	// FormatText prints human readable, coloured output.
+	FormatText Format = "text"
+	// FormatJSON prints a single Result document to stdout.
+	FormatJSON Format = "json"
+)
+
+// Formats lists all supported output formats.
+var Formats = []Format{
+	FormatText,
+	FormatJSON,
+}
+
+var (
+	format = FormatText
+
+	// resultWriter receives the result document.
+	resultWriter io.Writer = os.Stdout
+	// humanWriter receives the human readable output, it is moved to stderr
+	// with FormatJSON.
+	humanWriter io.Writer = os.Stdout
+)
+
+// Result is the document printed by every command with --output json.
+type Result struct {
+	Command     string       `json:"command"`
+	Success     bool         `json:"success"`
+	Connections []Connection `json:"connections,omitempty"`
+
+	// Health is the state of the workload cluster on the source MC.
+	Health string `json:"health,omitempty"`
+	// Apps reports which apps are migrated and which are skipped.
+	Apps *apps.Report `json:"apps,omitempty"`
+	// Files lists the files written by the command.
+	Files []string `json:"files,omitempty"`
+	// Objects holds the per object outcome, eg. the objects applied.
+	Objects interface{} `json:"objects,omitempty"`
+	// Verifications holds the state of the migrated apps.
+	Verifications []cluster.AppVerification `json:"verifications,omitempty"`
+	// Checks holds the prerequisite checks of the destination MC.
+	Checks []cluster.PrerequisiteCheck `json:"checks,omitempty"`
+
+	Warnings []string `json:"warnings,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// Connection is a management cluster the command is connected to.
+type Connection struct {
+	Role string `json:"role"`
+	Name string `json:"name"`
+}
+
+// SetFormat selects the output format. With FormatJSON all human readable
+// output is written to stderr, so stdout only carries the result document.
+// Human readable output must be written to HumanWriter or with color.
+func SetFormat(f Format) error {
+	switch f {
+	case FormatText:
+	case FormatJSON:
+		humanWriter = os.Stderr
+		color.Output = os.Stderr
+	default:
+		return microerror.Maskf(invalidFormatError, "output format must be one of %s", formatNames())
+	}
+
+	format = f
+
+	return nil
+}
+
+// HumanWriter returns the writer for human readable output, stdout or stderr
+// depending on the format.
+func HumanWriter() io.Writer {
+	return humanWriter
+}
+
+// IsJSON returns true if the result document is printed.
+func IsJSON() bool {
+	return format == FormatJSON
+}
+
+// NewResult creates an empty result for the given command.
+func NewResult(command string) *Result {
+	return &Result{
+		Command: command,
+	}
+}
+
+// AddConnections records both MCs of c.
+func (r *Result) AddConnections(c *cluster.Cluster) {
+	if c.SrcMC != nil {
+		r.Connections = append(r.Connections, Connection{Role: "source", Name: c.SrcMC.Name})
+	}
+
+	if c.DstMC != nil {
+		r.Connections = append(r.Connections, Connection{Role: "destination", Name: c.DstMC.Name})
+	}
+}
+
+// AddWarning records a warning which was printed to the user.
+func (r *Result) AddWarning(warning string) {
+	r.Warnings = append(r.Warnings, warning)
+}
+
+// Write completes the result with err and prints it if FormatJSON is
+// selected. It returns err, or the error writing the document.
+func Write(r *Result, err error) error {
+	r.Success = err == nil
+	if err != nil {
+		r.Errors = append(r.Errors, err.Error())
+	}
+
+	if IsJSON() {
+		encoder := json.NewEncoder(resultWriter)
+		encoder.SetIndent("", "  ")
+
+		encodeErr := encoder.Encode(r)
+		if encodeErr != nil && err == nil {
+			return microerror.Mask(encodeErr)
+		}
+	}
+
+	return err
+}
+
+func formatNames() string {
+	var names []string
+	for _, f := range Formats {
+		names = append(names, string(f))
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+
+	"github.com/giantswarm/app-migration-cli/pkg/cluster"
+)
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	format, resultWriter = FormatJSON, &buf
+	defer func() { format, resultWriter = FormatText, os.Stdout }()
+
+	result := NewResult("apply")
+	result.AddConnections(&cluster.Cluster{
+		SrcMC: &cluster.ManagementCluster{Name: "gauss"},
+		DstMC: &cluster.ManagementCluster{Name: "golem"},
+	})
+	result.Objects = []cluster.AppliedObject{{Kind: "App", Name: "wc1-loki", Namespace: "org-foo", Result: cluster.ApplyResultCreated}}
+	result.AddWarning("something is odd")
+
+	applyErr := errors.New("apply failed")
+	err := Write(result, applyErr)
+	if !errors.Is(err, applyErr) {
+		t.Fatalf("Write should return the command error. Is: %v; Want: %v", err, applyErr)
+	}
+
+	var written map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &written)
+	if err != nil {
+		t.Fatalf("Result is not valid JSON: %s", err)
+	}
+
+	if written["success"] != false {
+		t.Fatalf("Success is wrong. Is: %v; Want: %v", written["success"], false)
+	}
+
+	if len(written["connections"].([]interface{})) != 2 {
+		t.Fatalf("Number of connections is wrong. Is: %v; Want: %d", written["connections"], 2)
+	}
+
+	object := written["objects"].([]interface{})[0].(map[string]interface{})
+	if object["result"] != string(cluster.ApplyResultCreated) {
+		t.Fatalf("Object result is wrong. Is: %v; Want: %s", object["result"], cluster.ApplyResultCreated)
+	}
+
+	if written["errors"].([]interface{})[0] != "apply failed" {
+		t.Fatalf("Errors are wrong. Is: %v", written["errors"])
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	resultWriter = &buf
+	defer func() { resultWriter = os.Stdout }()
+
+	err := Write(NewResult("preflight"), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("Nothing should be written with text output. Is: %s", buf.String())
+	}
+}
+
+func TestSetFormatInvalid(t *testing.T) {
+	err := SetFormat("yaml")
+	if !errors.Is(err, invalidFormatError) {
+		t.Fatalf("Invalid format should be rejected. Is: %v", err)
+	}
+}
+
+func TestSetFormatJSON(t *testing.T) {
+	stdout, colorOutput := os.Stdout, color.Output
+	defer func() { format, humanWriter, color.Output = FormatText, os.Stdout, colorOutput }()
+
+	err := SetFormat(FormatJSON)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if os.Stdout != stdout {
+		t.Fatalf("os.Stdout must not be replaced")
+	}
+
+	if HumanWriter() != os.Stderr || color.Output != os.Stderr {
+		t.Fatalf("Human output should be written to stderr")
+	}
+}
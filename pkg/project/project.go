@@ -0,0 +1,34 @@
+package project
+
+var (
+	buildTimestamp = "n/a"
+	description    = "A Giantswarm tool to migrate apps between MCs"
+	gitSHA         = "n/a"
+	name           = "app-migration-cli"
+	source         = "https://github.com/giantswarm/app-migration-cli"
+	version        = "0.3.1-dev"
+)
+
+func BuildTimestamp() string {
+	return buildTimestamp
+}
+
+func Description() string {
+	return description
+}
+
+func GitSHA() string {
+	return gitSHA
+}
+
+func Name() string {
+	return name
+}
+
+func Source() string {
+	return source
+}
+
+func Version() string {
+	return version
+}